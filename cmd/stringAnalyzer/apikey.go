@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/auth"
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/database"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+const apiKeyUsage = `usage:
+  stringAnalyzer apikey create -name NAME -scopes read,write,delete,admin
+  stringAnalyzer apikey list
+  stringAnalyzer apikey revoke ID`
+
+// runAPIKeyCommand manages API keys directly in the configured database and
+// returns the process exit code.
+func runAPIKeyCommand(logger *zerolog.Logger, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, apiKeyUsage)
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to load config")
+		return 1
+	}
+	if cfg.Database.Driver == config.DriverMemory {
+		fmt.Fprintln(os.Stderr, "the memory driver keeps no keys between processes; use AUTH_BOOTSTRAP_KEY instead")
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err = database.Migrate(ctx, logger, cfg); err != nil {
+		logger.Error().Err(err).Msg("failed to migrate database")
+		return 1
+	}
+
+	db, err := database.New(cfg, logger)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to initialize database")
+		return 1
+	}
+	defer db.Close()
+
+	var keys repository.APIKeyStore
+	if cfg.Database.Driver == config.DriverSQLite {
+		keys = repository.NewSQLiteStore(logger, db)
+	} else {
+		keys = repository.NewAPIKeyRepository(logger, db)
+	}
+
+	switch args[0] {
+	case "create":
+		err = createAPIKey(ctx, keys, args[1:])
+	case "list":
+		err = listAPIKeys(ctx, keys)
+	case "revoke":
+		err = revokeAPIKey(ctx, keys, args[1:])
+	default:
+		err = fmt.Errorf("unknown apikey command %q\n%s", args[0], apiKeyUsage)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func createAPIKey(ctx context.Context, keys repository.APIKeyStore, args []string) error {
+	flags := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+	name := flags.String("name", "", "name describing who holds the key")
+	scopes := flags.String("scopes", auth.ScopeRead, "comma-separated scopes: "+strings.Join(auth.Scopes, ", "))
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return errors.New("-name is required")
+	}
+
+	key, record, err := auth.IssueKey(ctx, keys, *name, strings.Split(*scopes, ","))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created API key %d (%s) with scopes %s.\n", record.ID, record.Name, strings.Join(record.Scopes, ","))
+	fmt.Println("Store it now; it cannot be shown again:")
+	fmt.Println(key)
+	return nil
+}
+
+func listAPIKeys(ctx context.Context, keys repository.APIKeyStore) error {
+	records, err := keys.ListAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tCREATED\tREVOKED")
+	for _, record := range records {
+		revoked := "-"
+		if record.RevokedAt != nil {
+			revoked = record.RevokedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			record.ID, record.Name, record.Prefix, strings.Join(record.Scopes, ","),
+			record.CreatedAt.Format(time.RFC3339), revoked)
+	}
+	return tw.Flush()
+}
+
+func revokeAPIKey(ctx context.Context, keys repository.APIKeyStore, args []string) error {
+	if len(args) != 1 {
+		return errors.New(apiKeyUsage)
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid key id %q", args[0])
+	}
+
+	if err = keys.RevokeAPIKey(ctx, id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked API key %d.\n", id)
+	return nil
+}
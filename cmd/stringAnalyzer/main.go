@@ -14,12 +14,20 @@ import (
 	"github.com/justinndidit/stringAnalyzer/internal/logger"
 	"github.com/justinndidit/stringAnalyzer/internal/routes"
 	"github.com/justinndidit/stringAnalyzer/internal/server"
+	"github.com/justinndidit/stringAnalyzer/internal/tracing"
+	"github.com/rs/zerolog"
 )
 
 const DefaultContextTimeout = 30
 
 func main() {
 	logger := logger.NewLogger()
+
+	if len(os.Args) > 1 && os.Args[1] == "apikey" {
+		cliLogger := logger.Level(zerolog.WarnLevel)
+		os.Exit(runAPIKeyCommand(&cliLogger, os.Args[2:]))
+	}
+
 	logger.Info().Msg("Application starting...")
 
 	cfg, err := config.LoadConfig()
@@ -28,25 +36,39 @@ func main() {
 	}
 	logger.Info().Msg("Config loaded successfully")
 
-	// Migration with timeout
-	migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancelMigrate()
+	shutdownTracing, err := tracing.Setup(context.Background(), &cfg.Tracing)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to set up tracing")
+	}
+	logger.Info().Str("exporter", cfg.Tracing.Exporter).Msg("Tracing configured")
+
+	var db *database.Database
+	if cfg.Database.Driver != config.DriverMemory {
+		// Migration with timeout
+		migrateCtx, cancelMigrate := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancelMigrate()
 
-	logger.Info().Msg("Starting database migration...")
-	if err = database.Migrate(migrateCtx, &logger, cfg); err != nil {
-		logger.Fatal().Err(err).Msg("failed to migrate database")
+		logger.Info().Msg("Starting database migration...")
+		if err = database.Migrate(migrateCtx, &logger, cfg); err != nil {
+			logger.Fatal().Err(err).Msg("failed to migrate database")
+		}
+		logger.Info().Msg("Database migration completed")
+
+		// Database connection
+		logger.Info().Msg("Connecting to database...")
+		db, err = database.New(cfg, &logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to initialize database")
+		}
+		logger.Info().Msg("Database connected successfully")
+	} else {
+		logger.Warn().Str("driver", cfg.Database.Driver).Msg("Using non-persistent storage backend")
 	}
-	logger.Info().Msg("Database migration completed")
 
-	// Database connection
-	logger.Info().Msg("Connecting to database...")
-	db, err := database.New(cfg, &logger)
+	app, err := application.NewApp(cfg, &logger, db)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to initialize database")
+		logger.Fatal().Err(err).Msg("failed to initialize application")
 	}
-	logger.Info().Msg("Database connected successfully")
-
-	app := application.NewApp(cfg, &logger, db)
 	r := routes.SetupAuthRoutes(app)
 
 	srv, err := server.New(app, cfg)
@@ -56,6 +78,9 @@ func main() {
 
 	srv.SetupHTTPServer(r)
 
+	app.Purger.Start()
+	app.Reaper.Start()
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
@@ -81,5 +106,9 @@ func main() {
 		logger.Fatal().Err(err).Msg("server forced to shutdown")
 	}
 
+	if err = shutdownTracing(shutdownCtx); err != nil {
+		logger.Error().Err(err).Msg("failed to flush traces")
+	}
+
 	logger.Info().Msg("server exited properly")
 }
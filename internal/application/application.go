@@ -1,29 +1,90 @@
 package application
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/auth"
+	"github.com/justinndidit/stringAnalyzer/internal/cache"
 	"github.com/justinndidit/stringAnalyzer/internal/config"
 	"github.com/justinndidit/stringAnalyzer/internal/database"
 	"github.com/justinndidit/stringAnalyzer/internal/handler"
+	"github.com/justinndidit/stringAnalyzer/internal/metrics"
+	"github.com/justinndidit/stringAnalyzer/internal/ratelimit"
 	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/justinndidit/stringAnalyzer/internal/worker"
 	"github.com/rs/zerolog"
 )
 
 type Application struct {
-	Config  *config.Config
-	Logger  *zerolog.Logger
-	DB      *database.Database
-	Handler *handler.StringAnalyzerHandler
-	repo    *repository.StringRepository
+	Config     *config.Config
+	Logger     *zerolog.Logger
+	DB         *database.Database
+	Handler    *handler.StringAnalyzerHandler
+	Purger     *worker.TrashPurger
+	Reaper     *worker.ExpiryReaper
+	Cache      cache.Cache
+	Auth       *auth.Authenticator
+	Limiter    *ratelimit.Limiter
+	repo       repository.StringStore
+	namespaces repository.NamespaceStore
+	audit      repository.AuditStore
 }
 
-func NewApp(cfg *config.Config, logger *zerolog.Logger, db *database.Database) *Application {
-	repo := repository.NewStringRepository(logger, db)
-	handler := handler.NewStringAnalyzerHandler(logger, db, repo)
-	return &Application{
-		Config:  cfg,
-		Logger:  logger,
-		DB:      db,
-		repo:    repo,
-		Handler: handler,
+// NewApp wires the application against the storage backend selected by
+// cfg.Database.Driver. db is nil for the memory driver.
+func NewApp(cfg *config.Config, logger *zerolog.Logger, db *database.Database) (*Application, error) {
+	var (
+		repo       repository.StringStore
+		namespaces repository.NamespaceStore
+		audit      repository.AuditStore
+		keys       repository.APIKeyStore
+	)
+
+	switch cfg.Database.Driver {
+	case config.DriverMemory:
+		store := repository.NewMemoryStore(logger)
+		repo, namespaces, audit, keys = store, store, store, store
+	case config.DriverSQLite:
+		store := repository.NewSQLiteStore(logger, db)
+		repo, namespaces, audit, keys = store, store, store, store
+	default:
+		repo = repository.NewStringRepository(logger, db, cfg)
+		namespaces = repository.NewNamespaceRepository(logger, db)
+		audit = repository.NewAuditRepository(logger, db)
+		keys = repository.NewAPIKeyRepository(logger, db)
+	}
+
+	var c cache.Cache
+	if cfg.Cache.Enabled {
+		c = cache.NewLRU(cfg.Cache.Size, time.Duration(cfg.Cache.TTL)*time.Second)
+		cached := repository.NewCachedStore(logger, repo, namespaces, c)
+		repo, namespaces = cached, cached
 	}
+
+	metrics.RegisterDatabase(db)
+	metrics.RegisterCache(c)
+
+	authenticator, err := auth.NewAuthenticator(logger, &cfg.Auth, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up authentication: %w", err)
+	}
+
+	handler := handler.NewStringAnalyzerHandler(logger, repo, namespaces, audit, keys, c, cfg)
+	purger := worker.NewTrashPurger(logger, repo, cfg)
+	reaper := worker.NewExpiryReaper(logger, repo, cfg)
+	return &Application{
+		Config:     cfg,
+		Logger:     logger,
+		DB:         db,
+		repo:       repo,
+		namespaces: namespaces,
+		audit:      audit,
+		Handler:    handler,
+		Purger:     purger,
+		Reaper:     reaper,
+		Cache:      c,
+		Auth:       authenticator,
+		Limiter:    ratelimit.NewLimiter(logger, &cfg.RateLimit),
+	}, nil
 }
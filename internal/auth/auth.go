@@ -0,0 +1,148 @@
+// Package auth authenticates requests with API keys or OIDC bearer JWTs and
+// enforces the scopes each route requires.
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+)
+
+// APIKeyHeader is an alternative to "Authorization: Bearer <key>".
+const APIKeyHeader = "X-API-Key"
+
+type Authenticator struct {
+	logger       *zerolog.Logger
+	keys         repository.APIKeyStore
+	enabled      bool
+	bootstrapKey string
+	jwt          *jwtVerifier // nil unless an issuer is configured
+}
+
+func NewAuthenticator(logger *zerolog.Logger, cfg *config.AuthConfig, keys repository.APIKeyStore) (*Authenticator, error) {
+	a := &Authenticator{
+		logger:       logger,
+		keys:         keys,
+		enabled:      cfg.Enabled,
+		bootstrapKey: cfg.BootstrapKey,
+	}
+
+	if cfg.Enabled && cfg.JWTIssuer != "" {
+		verifier, err := newJWTVerifier(logger, cfg)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+
+	return a, nil
+}
+
+// Authenticate rejects requests without a valid API key or bearer JWT and
+// stores the caller's Principal in the request context. The caller also
+// becomes the request's actor, overriding any X-Actor header, and is added to
+// the request's log lines as "subject". It does nothing when authentication
+// is disabled.
+func (a *Authenticator) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := credential(r)
+		if token == "" {
+			unauthorized(w, r, "An API key or bearer token is required")
+			return
+		}
+
+		var (
+			principal *Principal
+			err       error
+		)
+		if a.jwt != nil && looksLikeJWT(token) {
+			principal, err = a.jwt.verify(r.Context(), token)
+		} else {
+			principal, err = a.principal(r, token)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, errs.ErrAPIKeyNotFound):
+				unauthorized(w, r, "Invalid or revoked API key")
+				return
+			case errors.Is(err, errInvalidToken):
+				requestctx.Logger(r.Context(), a.logger).Warn().Err(err).Msg("rejected bearer token")
+				unauthorized(w, r, "Invalid or expired bearer token")
+				return
+			}
+
+			requestctx.Logger(r.Context(), a.logger).Error().Err(err).Msg("error authenticating api key")
+			util.WriteProblem(w, r, errs.Internal())
+			return
+		}
+
+		// The request logger is shared with the access log, so updating it
+		// in place tags that line with the subject too.
+		if logger := requestctx.Logger(r.Context(), nil); logger != nil {
+			logger.UpdateContext(func(c zerolog.Context) zerolog.Context {
+				return c.Str("subject", principal.Subject)
+			})
+		}
+
+		ctx := WithPrincipal(r.Context(), principal)
+		ctx = requestctx.WithActor(ctx, principal.Subject)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Require rejects requests whose principal lacks scope. It must run after
+// Authenticate, and does nothing when authentication is disabled.
+func (a *Authenticator) Require(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if a.enabled {
+				principal := PrincipalFrom(r.Context())
+				if principal == nil || !principal.HasScope(scope) {
+					util.WriteProblem(w, r, errs.NewProblem(errs.CodeInsufficientScope, "These credentials lack the \""+scope+"\" scope"))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (a *Authenticator) principal(r *http.Request, key string) (*Principal, error) {
+	if a.bootstrapKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(a.bootstrapKey)) == 1 {
+		return &Principal{Subject: "apikey:bootstrap", Scopes: []string{ScopeAdmin}}, nil
+	}
+
+	record, err := a.keys.GetAPIKeyByHash(r.Context(), HashKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{Subject: "apikey:" + record.Prefix, Scopes: record.Scopes}, nil
+}
+
+// credential returns the key sent as a bearer token or in X-API-Key.
+func credential(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return strings.TrimSpace(r.Header.Get(APIKeyHeader))
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, detail string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="stringAnalyzer"`)
+	util.WriteProblem(w, r, errs.NewProblem(errs.CodeUnauthorized, detail))
+}
@@ -0,0 +1,278 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// minJWKSRefetch limits how often an unknown key ID can trigger a fetch, so
+// tokens with made-up kids cannot hammer the identity provider.
+const minJWKSRefetch = time.Minute
+
+// keySet holds the public keys tokens are verified against, loaded from a
+// JWKS URL or a local file.
+type keySet struct {
+	url             string
+	file            string
+	refreshInterval time.Duration
+	client          *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]any // kid -> public key
+	fetchedAt time.Time
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// lookup returns the key with the given kid. A token without a kid is
+// accepted when the set holds exactly one key. Keys from a URL are re-fetched
+// once they are older than the refresh interval, or when kid is unknown.
+func (s *keySet) lookup(ctx context.Context, kid string) (any, error) {
+	s.mu.RLock()
+	key, ok := s.find(kid)
+	age := time.Since(s.fetchedAt)
+	s.mu.RUnlock()
+
+	if s.url != "" && (age > s.refreshInterval || (!ok && age > minJWKSRefetch)) {
+		if err := s.load(ctx); err != nil && !ok {
+			return nil, err
+		}
+
+		s.mu.RLock()
+		key, ok = s.find(kid)
+		s.mu.RUnlock()
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("no signing key with kid %q", kid)
+	}
+	return key, nil
+}
+
+// find must be called with s.mu held.
+func (s *keySet) find(kid string) (any, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *keySet) load(ctx context.Context) error {
+	var (
+		data []byte
+		err  error
+	)
+	if s.url != "" {
+		data, err = s.fetch(ctx)
+	} else {
+		data, err = os.ReadFile(s.file)
+	}
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseKeys(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys, s.fetchedAt = keys, time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *keySet) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build JWKS request: %w", err)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", res.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, 1<<20))
+}
+
+// parseKeys reads a JWKS document or, failing that, PEM-encoded public keys.
+// PEM keys are identified by their position ("0", "1", ...) unless the block
+// carries a "kid" header. Keys that cannot verify signatures are skipped; it
+// fails only when none are left.
+func parseKeys(data []byte) (map[string]any, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err == nil {
+		keys := map[string]any{}
+		var skipped error
+		for _, k := range doc.Keys {
+			if k.Use != "" && k.Use != "sig" {
+				continue
+			}
+			key, err := k.publicKey()
+			if err != nil {
+				// Providers publish keys of types and curves we do not
+				// support alongside ones we do; only the usable ones count.
+				skipped = fmt.Errorf("invalid JWK %q: %w", k.Kid, err)
+				continue
+			}
+			keys[k.Kid] = key
+		}
+		if len(keys) == 0 {
+			if skipped != nil {
+				return nil, fmt.Errorf("JWKS contains no usable signing keys: %w", skipped)
+			}
+			return nil, errors.New("JWKS contains no signing keys")
+		}
+		return keys, nil
+	}
+
+	keys := map[string]any{}
+	var skipped error
+	blocks := 0
+	for rest := data; ; blocks++ {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err == nil && !isSigningKey(key) {
+			err = fmt.Errorf("unsupported key type %T", key)
+		}
+		if err != nil {
+			skipped = fmt.Errorf("invalid PEM public key: %w", err)
+			continue
+		}
+
+		kid := block.Headers["kid"]
+		if kid == "" {
+			kid = strconv.Itoa(blocks)
+		}
+		keys[kid] = key
+	}
+	if len(keys) == 0 {
+		if skipped != nil {
+			return nil, fmt.Errorf("key file contains no usable signing keys: %w", skipped)
+		}
+		return nil, errors.New("key file contains neither a JWKS document nor PEM public keys")
+	}
+	return keys, nil
+}
+
+// isSigningKey reports whether key is of a type tokens can be signed with.
+func isSigningKey(key any) bool {
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey, ed25519.PublicKey:
+		return true
+	}
+	return false
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		// Coordinates are fixed-width in the uncompressed encoding.
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) > size || len(y) > size {
+			return nil, errors.New("invalid EC coordinate length")
+		}
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		copy(point[1+size-len(x):1+size], x)
+		copy(point[1+2*size-len(y):], y)
+		return ecdsa.ParseUncompressedPublicKey(curve, point)
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key length")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+)
+
+func TestParseKeysReadsEachKeyType(t *testing.T) {
+	keys := newTestKeys(t)
+
+	parsed, err := parseKeys(keys.jwks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 3 {
+		t.Fatalf("parsed %d keys, want 3", len(parsed))
+	}
+	if key, ok := parsed["rsa"].(*rsa.PublicKey); !ok || !key.Equal(keys.rsa.Public()) {
+		t.Errorf("rsa = %v, want the RSA public key", parsed["rsa"])
+	}
+	if key, ok := parsed["ec"].(*ecdsa.PublicKey); !ok || !key.Equal(keys.ecdsa.Public()) {
+		t.Errorf("ec = %v, want the ECDSA public key", parsed["ec"])
+	}
+	if key, ok := parsed["ed"].(ed25519.PublicKey); !ok || !key.Equal(keys.ed25519.Public()) {
+		t.Errorf("ed = %v, want the Ed25519 public key", parsed["ed"])
+	}
+
+	pems := append(pemBlock(t, keys.rsa, ""), pemBlock(t, keys.ecdsa, "ec")...)
+	pems = append(pems, pemBlock(t, keys.ed25519, "")...)
+	parsed, err = parseKeys(pems)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kid := range []string{"0", "ec", "2"} {
+		if parsed[kid] == nil {
+			t.Errorf("no PEM key with kid %q in %v", kid, parsed)
+		}
+	}
+}
+
+func TestParseKeysSkipsUnusableKeys(t *testing.T) {
+	keys := newTestKeys(t)
+
+	var doc struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	if err := json.Unmarshal(keys.jwks(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	usable := doc.Keys
+	unusable := []map[string]string{
+		{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+		{"kty": "EC", "kid": "k1", "crv": "secp256k1", "x": "AA", "y": "AA"},
+		{"kty": "OKP", "kid": "x25519", "crv": "X25519", "x": "AA"},
+		{"kty": "RSA", "kid": "broken", "n": "not base64!", "e": "AQAB"},
+	}
+
+	doc.Keys = append(append([]map[string]string{}, unusable...), usable...)
+	data, _ := json.Marshal(doc)
+	parsed, err := parseKeys(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != len(usable) {
+		t.Errorf("parsed %v, want only the usable keys", parsed)
+	}
+
+	doc.Keys = unusable
+	data, _ = json.Marshal(doc)
+	if _, err := parseKeys(data); err == nil {
+		t.Error("a JWKS with no usable key was accepted")
+	}
+
+	// X25519 keys parse as PEM but cannot verify signatures.
+	xKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(xKey.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	x25519 := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	parsed, err = parseKeys(append(x25519, pemBlock(t, keys.ed25519, "")...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 1 || parsed["1"] == nil {
+		t.Errorf("parsed %v, want the Ed25519 key at position 1", parsed)
+	}
+
+	if _, err := parseKeys(x25519); err == nil {
+		t.Error("a PEM file with no usable key was accepted")
+	}
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// errInvalidToken is returned for bearer JWTs that fail verification.
+var errInvalidToken = errors.New("invalid token")
+
+// jwtLeeway absorbs clock skew between us and the identity provider.
+const jwtLeeway = 30 * time.Second
+
+// jwtVerifier checks bearer JWTs from the configured OIDC issuer and maps
+// their claims onto a Principal.
+type jwtVerifier struct {
+	keys         *keySet
+	parser       *jwt.Parser
+	subjectClaim string
+	scopeClaim   string
+	scopePrefix  string
+}
+
+func newJWTVerifier(logger *zerolog.Logger, cfg *config.AuthConfig) (*jwtVerifier, error) {
+	if cfg.JWKSURL == "" && cfg.JWKSFile == "" {
+		return nil, errors.New("AUTH_JWT_ISSUER requires AUTH_JWKS_URL or AUTH_JWKS_FILE")
+	}
+
+	keys := &keySet{
+		url:             cfg.JWKSURL,
+		file:            cfg.JWKSFile,
+		refreshInterval: time.Duration(cfg.JWKSRefreshInterval) * time.Second,
+		client:          &http.Client{Timeout: 10 * time.Second},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := keys.load(ctx); err != nil {
+		if keys.url == "" {
+			return nil, fmt.Errorf("failed to load signing keys: %w", err)
+		}
+		// The identity provider may be briefly unreachable at startup;
+		// the keys are fetched again on first use.
+		logger.Warn().Err(err).Str("url", keys.url).Msg("could not fetch JWKS")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithIssuer(cfg.JWTIssuer),
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtLeeway),
+	}
+	if cfg.JWTAudience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.JWTAudience))
+	}
+
+	return &jwtVerifier{
+		keys:         keys,
+		parser:       jwt.NewParser(opts...),
+		subjectClaim: cfg.JWTSubjectClaim,
+		scopeClaim:   cfg.JWTScopeClaim,
+		scopePrefix:  cfg.JWTScopePrefix,
+	}, nil
+}
+
+// looksLikeJWT tells a compact JWS apart from an API key, which has no dots.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+func (v *jwtVerifier) verify(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.lookup(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidToken, err)
+	}
+
+	subject, _ := claims[v.subjectClaim].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing %q claim", errInvalidToken, v.subjectClaim)
+	}
+
+	return &Principal{Subject: subject, Scopes: v.scopes(claims[v.scopeClaim])}, nil
+}
+
+// scopes maps the scope claim, a space-separated string or an array, onto
+// the service's scopes. Values without the configured prefix, or naming no
+// known scope once it is removed, are ignored.
+func (v *jwtVerifier) scopes(claim any) []string {
+	var values []string
+	switch c := claim.(type) {
+	case string:
+		values = strings.Fields(c)
+	case []any:
+		for _, value := range c {
+			if s, ok := value.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	scopes := []string{}
+	for _, value := range values {
+		scope, ok := strings.CutPrefix(value, v.scopePrefix)
+		if ok && IsValidScope(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
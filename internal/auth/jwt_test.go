@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/rs/zerolog"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "string-analyzer"
+)
+
+// testKeys holds a signing key of each supported type.
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKeys{rsa: rsaKey, ecdsa: ecKey, ed25519: edKey}
+}
+
+// jwks renders the public halves of the keys as a JWKS document.
+func (k testKeys) jwks() []byte {
+	b64 := base64.RawURLEncoding.EncodeToString
+	ecX, ecY := k.ecdsa.X.FillBytes(make([]byte, 32)), k.ecdsa.Y.FillBytes(make([]byte, 32))
+
+	doc := map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecX), "y": b64(ecY)},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(k.ed25519.Public().(ed25519.PublicKey))},
+	}}
+	data, _ := json.Marshal(doc)
+	return data
+}
+
+// pemBlock encodes the public half of key, with kid as a header when set.
+func pemBlock(t *testing.T, key crypto.Signer, kid string) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	if kid != "" {
+		block.Headers = map[string]string{"kid": kid}
+	}
+	return pem.EncodeToMemory(block)
+}
+
+// newTestVerifier returns a verifier reading its keys from a file holding
+// data.
+func newTestVerifier(t *testing.T, data []byte) *jwtVerifier {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := zerolog.Nop()
+	v, err := newJWTVerifier(&logger, &config.AuthConfig{
+		JWTIssuer:           testIssuer,
+		JWKSFile:            file,
+		JWKSRefreshInterval: 3600,
+		JWTAudience:         testAudience,
+		JWTSubjectClaim:     "sub",
+		JWTScopeClaim:       "scope",
+		JWTScopePrefix:      "analyzer:",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// claims returns valid claims for a token, with changes applied on top.
+// A nil value removes the claim.
+func claims(changes jwt.MapClaims) jwt.MapClaims {
+	c := jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "user-1",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "analyzer:read analyzer:write",
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(c, name)
+			continue
+		}
+		c[name] = value
+	}
+	return c
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, c jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, c)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestVerifyWithJWKSFile(t *testing.T) {
+	keys := newTestKeys(t)
+	v := newTestVerifier(t, keys.jwks())
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		reject bool
+	}{
+		{"RS256", sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa", claims(nil)), false},
+		{"PS256", sign(t, jwt.SigningMethodPS256, keys.rsa, "rsa", claims(nil)), false},
+		{"ES256", sign(t, jwt.SigningMethodES256, keys.ecdsa, "ec", claims(nil)), false},
+		{"EdDSA", sign(t, jwt.SigningMethodEdDSA, keys.ed25519, "ed", claims(nil)), false},
+		{"within leeway", sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa", claims(jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()})), false},
+
+		{"expired", sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})), true},
+		{"no expiry", sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa", claims(jwt.MapClaims{"exp": nil})), true},
+		{"wrong issuer", sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa", claims(jwt.MapClaims{"iss": "https://evil.example.com"})), true},
+		{"wrong audience", sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa", claims(jwt.MapClaims{"aud": "another-service"})), true},
+		{"unknown kid", sign(t, jwt.SigningMethodRS256, keys.rsa, "rotated", claims(nil)), true},
+		{"no kid with several keys", sign(t, jwt.SigningMethodRS256, keys.rsa, "", claims(nil)), true},
+		{"signed by another key", sign(t, jwt.SigningMethodES256, keys.ecdsa, "rsa", claims(nil)), true},
+		{"no subject", sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa", claims(jwt.MapClaims{"sub": nil})), true},
+		{"empty subject", sign(t, jwt.SigningMethodRS256, keys.rsa, "rsa", claims(jwt.MapClaims{"sub": ""})), true},
+		{"unsigned", sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "rsa", claims(nil)), true},
+	} {
+		principal, err := v.verify(context.Background(), tc.token)
+		if tc.reject {
+			if !errors.Is(err, errInvalidToken) {
+				t.Errorf("%s: error %v, want errInvalidToken", tc.name, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+			continue
+		}
+		if principal.Subject != "user-1" || !reflect.DeepEqual(principal.Scopes, []string{ScopeRead, ScopeWrite}) {
+			t.Errorf("%s: principal %+v", tc.name, principal)
+		}
+	}
+}
+
+func TestVerifyWithPEMFile(t *testing.T) {
+	keys := newTestKeys(t)
+
+	// A single key needs no kid.
+	v := newTestVerifier(t, pemBlock(t, keys.rsa, ""))
+	for _, kid := range []string{"", "0"} {
+		if _, err := v.verify(context.Background(), sign(t, jwt.SigningMethodRS256, keys.rsa, kid, claims(nil))); err != nil {
+			t.Errorf("kid %q: unexpected error %v", kid, err)
+		}
+	}
+
+	// Several keys are told apart by their kid header or position.
+	data := append(pemBlock(t, keys.ecdsa, ""), pemBlock(t, keys.ed25519, "ed")...)
+	v = newTestVerifier(t, data)
+	for _, tc := range []struct {
+		token  string
+		reject bool
+	}{
+		{sign(t, jwt.SigningMethodES256, keys.ecdsa, "0", claims(nil)), false},
+		{sign(t, jwt.SigningMethodEdDSA, keys.ed25519, "ed", claims(nil)), false},
+		{sign(t, jwt.SigningMethodEdDSA, keys.ed25519, "1", claims(nil)), true},
+		{sign(t, jwt.SigningMethodES256, keys.ecdsa, "", claims(nil)), true},
+		{sign(t, jwt.SigningMethodES256, keys.ecdsa, "0", claims(jwt.MapClaims{"iss": "https://evil.example.com"})), true},
+	} {
+		_, err := v.verify(context.Background(), tc.token)
+		if tc.reject != (err != nil) {
+			t.Errorf("token %s: error %v, want rejected %v", tc.token, err, tc.reject)
+		}
+	}
+}
+
+func TestScopes(t *testing.T) {
+	v := &jwtVerifier{scopePrefix: "analyzer:"}
+
+	for _, tc := range []struct {
+		claim any
+		want  []string
+	}{
+		{"analyzer:read analyzer:delete", []string{ScopeRead, ScopeDelete}},
+		{[]any{"analyzer:admin", "analyzer:write"}, []string{ScopeAdmin, ScopeWrite}},
+		// Scopes without the prefix, or unknown once it is removed, are not
+		// granted.
+		{"read analyzer:read", []string{ScopeRead}},
+		{"analyzer:superuser other:admin", []string{}},
+		{[]any{"analyzer:read", 42, nil}, []string{ScopeRead}},
+		{nil, []string{}},
+		{42, []string{}},
+	} {
+		if got := v.scopes(tc.claim); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("scopes(%v) = %v, want %v", tc.claim, got, tc.want)
+		}
+	}
+
+	// Without a prefix, scope values are taken as they are.
+	v = &jwtVerifier{}
+	if got := v.scopes("read analyzer:write"); !reflect.DeepEqual(got, []string{ScopeRead}) {
+		t.Errorf("scopes without a prefix = %v, want [read]", got)
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+)
+
+const (
+	// keyPrefix marks API keys so they are recognisable in config files and
+	// secret scanners.
+	keyPrefix = "sak_"
+	// keyBytes is the amount of randomness in a key.
+	keyBytes = 32
+	// displayPrefixLength is how much of a key is stored in the clear so
+	// that holders can tell their keys apart.
+	displayPrefixLength = len(keyPrefix) + 8
+)
+
+// HashKey returns the hex SHA-256 of key, which is what the store keeps.
+// Keys carry 256 random bits, so a fast hash is enough.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueKey generates a new API key, stores its hash and returns the key.
+// The key cannot be recovered afterwards.
+func IssueKey(ctx context.Context, keys repository.APIKeyStore, name string, scopes []string) (string, *model.APIKey, error) {
+	for _, scope := range scopes {
+		if !IsValidScope(scope) {
+			return "", nil, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	secret := make([]byte, keyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	record, err := keys.CreateAPIKey(ctx, &dto.StoreAPIKey{
+		Name:   name,
+		Prefix: key[:displayPrefixLength],
+		Hash:   HashKey(key),
+		Scopes: scopes,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key, record, nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Scopes an API key can be granted. ScopeAdmin implies every other scope.
+const (
+	ScopeRead   = "read"
+	ScopeWrite  = "write"
+	ScopeDelete = "delete"
+	ScopeAdmin  = "admin"
+)
+
+// Scopes lists every valid scope.
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeDelete, ScopeAdmin}
+
+func IsValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller in logs and the audit trail.
+	Subject string
+	Scopes  []string
+}
+
+// HasScope reports whether the principal was granted scope, directly or
+// through ScopeAdmin.
+func (p *Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx that carries the principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored in ctx, or nil.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
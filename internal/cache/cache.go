@@ -0,0 +1,28 @@
+// Package cache provides the key/value cache that sits in front of the string
+// store. Values are opaque bytes so that an external cache can implement the
+// same interface as the in-process LRU.
+package cache
+
+// Cache stores byte values under string keys. Implementations must be safe for
+// concurrent use and may drop entries at any time.
+type Cache interface {
+	// Get returns the value stored under key and whether it was found.
+	Get(key string) ([]byte, bool)
+	// Set stores value under key, replacing any existing value.
+	Set(key string, value []byte)
+	// Delete removes key if present.
+	Delete(key string)
+	// DeletePrefix removes every key starting with prefix.
+	DeletePrefix(prefix string)
+	// Stats reports the cache's counters since it was created.
+	Stats() Stats
+}
+
+// Stats holds cache counters.
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+	Capacity  int    `json:"capacity"`
+}
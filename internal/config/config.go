@@ -162,8 +162,12 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	_ "github.com/joho/godotenv/autoload"
@@ -173,29 +177,363 @@ import (
 )
 
 type Config struct {
-	Database DatabaseConfig `koanf:"database" validate:"required"`
-	Server   ServerConfig   `koanf:"server" validate:"required"`
+	Database  DatabaseConfig  `koanf:"database" validate:"required"`
+	Server    ServerConfig    `koanf:"server" validate:"required"`
+	Search    SearchConfig    `koanf:"search" validate:"required"`
+	Trash     TrashConfig     `koanf:"trash" validate:"required"`
+	Expiry    ExpiryConfig    `koanf:"expiry" validate:"required"`
+	Cache     CacheConfig     `koanf:"cache" validate:"required"`
+	Tracing   TracingConfig   `koanf:"tracing" validate:"required"`
+	Auth      AuthConfig      `koanf:"auth" validate:"required"`
+	RateLimit RateLimitConfig `koanf:"ratelimit" validate:"required"`
+	Value     ValueConfig     `koanf:"value" validate:"required"`
+	API       APIConfig       `koanf:"api" validate:"required"`
 }
 
+// Storage backends selectable through DATABASE_DRIVER.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMemory   = "memory"
+)
+
 type DatabaseConfig struct {
-	Host            string `koanf:"host" validate:"required"`
-	Port            int    `koanf:"port" validate:"required"`
-	User            string `koanf:"user" validate:"required"`
+	Driver          string `koanf:"driver" validate:"required,oneof=postgres sqlite memory"`
+	Host            string `koanf:"host" validate:"required_if=Driver postgres"`
+	Port            int    `koanf:"port" validate:"required_if=Driver postgres"`
+	User            string `koanf:"user" validate:"required_if=Driver postgres"`
 	Password        string `koanf:"password"`
-	Name            string `koanf:"name" validate:"required"`
-	SSLMode         string `koanf:"ssl_mode" validate:"required"`
-	MaxOpenConns    int    `koanf:"max_open_conns" validate:"required"`
-	MaxIdleConns    int    `koanf:"max_idle_conns" validate:"required"`
-	ConnMaxLifetime int    `koanf:"conn_max_lifetime" validate:"required"`
-	ConnMaxIdleTime int    `koanf:"conn_max_idle_time" validate:"required"`
+	Name            string `koanf:"name" validate:"required_if=Driver postgres"`
+	SSLMode         string `koanf:"ssl_mode" validate:"required_if=Driver postgres"`
+	MaxOpenConns    int    `koanf:"max_open_conns" validate:"required_if=Driver postgres"`
+	MaxIdleConns    int    `koanf:"max_idle_conns" validate:"required_if=Driver postgres"`
+	ConnMaxLifetime int    `koanf:"conn_max_lifetime" validate:"required_if=Driver postgres"`
+	ConnMaxIdleTime int    `koanf:"conn_max_idle_time" validate:"required_if=Driver postgres"`
+	// Path is the SQLite database file.
+	Path string `koanf:"path" validate:"required_if=Driver sqlite"`
 }
 
 type ServerConfig struct {
-	Port               string   `koanf:"port" validate:"required"`
-	ReadTimeout        int      `koanf:"read_timeout" validate:"required"`
-	WriteTimeout       int      `koanf:"write_timeout" validate:"required"`
-	IdleTimeout        int      `koanf:"idle_timeout" validate:"required"`
+	Port         string `koanf:"port" validate:"required"`
+	ReadTimeout  int    `koanf:"read_timeout" validate:"required"`
+	WriteTimeout int    `koanf:"write_timeout" validate:"required"`
+	IdleTimeout  int    `koanf:"idle_timeout" validate:"required"`
+	// CORSAllowedOrigins lists the origins browsers may call the API from.
+	// "*" allows any origin and "https://*.example.com" any subdomain.
 	CORSAllowedOrigins []string `koanf:"cors_allowed_origins" validate:"required"`
+	// CORSAllowedMethods and CORSAllowedHeaders are what a preflight may ask
+	// for. A "*" header entry allows any header.
+	CORSAllowedMethods []string `koanf:"cors_allowed_methods" validate:"required"`
+	CORSAllowedHeaders []string `koanf:"cors_allowed_headers" validate:"required"`
+	// CORSExposedHeaders are the response headers scripts may read.
+	CORSExposedHeaders []string `koanf:"cors_exposed_headers"`
+	// CORSAllowCredentials lets browsers send cookies and Authorization.
+	CORSAllowCredentials bool `koanf:"cors_allow_credentials"`
+	// CORSMaxAge is how long, in seconds, browsers may cache a preflight.
+	CORSMaxAge int `koanf:"cors_max_age" validate:"gte=0"`
+	// MaxBodyBytes caps the size of any request body; larger ones are
+	// rejected with 413 before they are read.
+	MaxBodyBytes int64 `koanf:"max_body_bytes" validate:"gt=0"`
+}
+
+// Query parameter validation modes for GET /strings.
+const (
+	ParamValidationStrict  = "strict"
+	ParamValidationLenient = "lenient"
+)
+
+type SearchConfig struct {
+	// Language is the Postgres text search configuration used to build
+	// and query the full-text index, e.g. "english" or "simple". SQLite
+	// stems English and splits words only for anything else. Changing it
+	// rebuilds the index at the next startup, so every replica must agree.
+	Language string `koanf:"language" validate:"required"`
+	// ParamValidation is "strict" to reject unknown, repeated and
+	// unparseable filter parameters, or "lenient" to ignore them as older
+	// releases did.
+	ParamValidation string `koanf:"param_validation" validate:"required,oneof=strict lenient"`
+}
+
+type TrashConfig struct {
+	// Retention is how long, in seconds, deleted strings stay in the trash
+	// before the background purge removes them for good.
+	Retention int `koanf:"retention" validate:"required,gt=0"`
+	// PurgeInterval is how often, in seconds, the trash is purged.
+	PurgeInterval int `koanf:"purge_interval" validate:"required,gt=0"`
+}
+
+type ExpiryConfig struct {
+	// ReapInterval is how often, in seconds, expired strings are deleted.
+	ReapInterval int `koanf:"reap_interval" validate:"required,gt=0"`
+	// BatchSize caps how many expired strings one delete statement removes.
+	BatchSize int `koanf:"batch_size" validate:"required,gt=0"`
+}
+
+type CacheConfig struct {
+	// Enabled puts a read-through cache in front of the string store.
+	Enabled bool `koanf:"enabled"`
+	// Size is the maximum number of cached lookups and queries.
+	Size int `koanf:"size" validate:"required,gt=0"`
+	// TTL is how long, in seconds, a cached entry may be served.
+	TTL int `koanf:"ttl" validate:"required,gt=0"`
+}
+
+// Trace exporters selectable through TRACING_EXPORTER.
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterFile   = "file"
+	TracingExporterOTLP   = "otlp"
+)
+
+type TracingConfig struct {
+	// Exporter is where finished spans are sent. "none" disables tracing
+	// but still propagates incoming trace context.
+	Exporter string `koanf:"exporter" validate:"required,oneof=none stdout file otlp"`
+	// ServiceName is reported as the service.name resource attribute.
+	ServiceName string `koanf:"service_name" validate:"required"`
+	// SampleRatio is the fraction of new traces recorded. Traces started
+	// upstream follow the caller's sampling decision.
+	SampleRatio float64 `koanf:"sample_ratio" validate:"gte=0,lte=1"`
+	// FilePath is the file spans are appended to, one JSON document each.
+	FilePath string `koanf:"file_path" validate:"required_if=Exporter file"`
+	// Endpoint is the host:port of the OTLP/HTTP collector. When empty the
+	// standard OTEL_EXPORTER_OTLP_* variables apply.
+	Endpoint string `koanf:"endpoint"`
+	// Insecure sends OTLP over plain HTTP instead of HTTPS.
+	Insecure bool `koanf:"insecure"`
+}
+
+type AuthConfig struct {
+	// Enabled requires an API key with the route's scope on every request
+	// except the health check, the index and the docs page, which holds no
+	// data of its own.
+	Enabled bool `koanf:"enabled"`
+	// PublicMetrics serves /metrics without credentials; otherwise it
+	// requires the admin scope. PublicDocs does the same for
+	// /openapi.json, which otherwise requires the read scope.
+	PublicMetrics bool `koanf:"public_metrics"`
+	PublicDocs    bool `koanf:"public_docs"`
+	// BootstrapKey, when set, is accepted as an admin key in addition to
+	// the stored ones. It lets the first real key be created over HTTP and
+	// is the only way to authenticate with the memory driver.
+	BootstrapKey string `koanf:"bootstrap_key" validate:"omitempty,min=32"`
+
+	// JWTIssuer enables bearer JWT authentication for tokens issued by it.
+	// Signing keys come from JWKSURL or, for offline use, JWKSFile (a JWKS
+	// document or PEM public keys).
+	JWTIssuer string `koanf:"jwt_issuer" validate:"omitempty,url"`
+	JWKSURL   string `koanf:"jwks_url" validate:"omitempty,url"`
+	JWKSFile  string `koanf:"jwks_file" validate:"omitempty,file"`
+	// JWKSRefreshInterval is how often, in seconds, JWKSURL is re-fetched.
+	JWKSRefreshInterval int `koanf:"jwks_refresh_interval" validate:"gt=0"`
+	// JWTAudience, when set, must appear in the token's aud claim.
+	JWTAudience string `koanf:"jwt_audience"`
+	// JWTSubjectClaim names the claim identifying the acting user.
+	JWTSubjectClaim string `koanf:"jwt_subject_claim" validate:"required"`
+	// JWTScopeClaim names the claim listing the caller's scopes, either a
+	// space-separated string or an array. Only values starting with
+	// JWTScopePrefix count, with the prefix removed, e.g. "analyzer:read".
+	JWTScopeClaim  string `koanf:"jwt_scope_claim" validate:"required"`
+	JWTScopePrefix string `koanf:"jwt_scope_prefix"`
+}
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate per
+// second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimitConfig struct {
+	// Enabled limits each client, identified by its API key or JWT subject
+	// or, when unauthenticated, its IP address. Counters are kept in
+	// process, so each replica enforces its own limits.
+	Enabled bool `koanf:"enabled"`
+	// Rate and Burst are the limit applied to each route without one of
+	// its own in Routes.
+	Rate  float64 `koanf:"rate" validate:"gt=0"`
+	Burst int     `koanf:"burst" validate:"gt=0"`
+	// Routes overrides the limit for single routes, one "METHOD
+	// /chi/pattern=rate:burst" entry each, e.g. "GET /strings=2:10".
+	// Patterns leave out the API version, which shares them.
+	Routes []string `koanf:"routes"`
+	// Quotas caps how many requests each client may make to a route per UTC
+	// day, one "METHOD /chi/pattern=requests" entry each. Like Routes,
+	// patterns leave out the API version.
+	Quotas []string `koanf:"quotas"`
+	// TrustForwardedFor takes the client IP from X-Forwarded-For. Enable it
+	// only behind a proxy that sets the header.
+	TrustForwardedFor bool `koanf:"trust_forwarded_for"`
+	// AuthFailures limits, as "rate:burst", how many requests per IP
+	// address may fail authentication, so that credentials cannot be
+	// guessed at the rate of the per-client limits.
+	AuthFailures string `koanf:"auth_failures" validate:"required"`
+
+	// RouteLimits and RouteQuotas are Routes and Quotas parsed, keyed by
+	// "METHOD /chi/pattern", and AuthFailureLimit is AuthFailures parsed.
+	RouteLimits      map[string]Limit `koanf:"-"`
+	RouteQuotas      map[string]int   `koanf:"-"`
+	AuthFailureLimit Limit            `koanf:"-"`
+}
+
+// Policies for unwanted content in uploaded values.
+const (
+	PolicyReject  = "reject"
+	PolicyAllow   = "allow"
+	PolicyReplace = "replace"
+)
+
+type ValueConfig struct {
+	// MaxBytes and MaxRunes cap the length of an uploaded value in UTF-8
+	// bytes and in characters.
+	MaxBytes int `koanf:"max_bytes" validate:"gt=0"`
+	MaxRunes int `koanf:"max_runes" validate:"gt=0"`
+	// InvalidUTF8 rejects bodies that are not valid UTF-8, or replaces each
+	// invalid byte sequence with U+FFFD.
+	InvalidUTF8 string `koanf:"invalid_utf8" validate:"required,oneof=reject replace"`
+	// NULBytes decides whether values may contain U+0000. Postgres cannot
+	// store it in a TEXT column, so allow is refused with the postgres
+	// driver.
+	NULBytes string `koanf:"nul_bytes" validate:"required,oneof=reject allow"`
+	// ControlCharacters decides whether values may contain control
+	// characters other than tab, newline and carriage return.
+	ControlCharacters string `koanf:"control_characters" validate:"required,oneof=reject allow"`
+}
+
+type APIConfig struct {
+	// LegacyPaths keeps serving v1 at the unversioned paths it used before
+	// /v1 existed, with Deprecation and Sunset headers on every response.
+	LegacyPaths bool `koanf:"legacy_paths"`
+	// LegacyDeprecatedAt and LegacySunsetAt are RFC 3339 timestamps of when
+	// the unversioned paths were deprecated and when they will be removed.
+	LegacyDeprecatedAt string `koanf:"legacy_deprecated_at" validate:"required"`
+	LegacySunsetAt     string `koanf:"legacy_sunset_at" validate:"required"`
+
+	// LegacyDeprecation and LegacySunset are LegacyDeprecatedAt and
+	// LegacySunsetAt parsed.
+	LegacyDeprecation time.Time `koanf:"-"`
+	LegacySunset      time.Time `koanf:"-"`
+}
+
+// envSections maps environment variable prefixes to the config section they
+// populate, e.g. DATABASE_HOST -> database.host.
+var envSections = map[string]string{
+	"DATABASE_":  "database",
+	"SERVER_":    "server",
+	"SEARCH_":    "search",
+	"TRASH_":     "trash",
+	"EXPIRY_":    "expiry",
+	"CACHE_":     "cache",
+	"TRACING_":   "tracing",
+	"AUTH_":      "auth",
+	"RATELIMIT_": "ratelimit",
+	"VALUE_":     "value",
+	"API_":       "api",
+}
+
+// defaults holds values for optional settings that are not provided through
+// the environment.
+var defaults = map[string]any{
+	"database.driver":             DriverPostgres,
+	"server.cors_allowed_methods": []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+	"server.cors_allowed_headers": []string{"Content-Type", "X-Request-ID", "X-Actor", "X-Namespace", "Authorization", "X-API-Key", "traceparent", "tracestate", "If-Match", "If-None-Match"},
+	"server.cors_exposed_headers": []string{"X-Request-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After", "Deprecation", "Sunset", "Link", "ETag"},
+	"server.cors_max_age":         600,
+	"server.max_body_bytes":       1 << 20,
+	"search.language":             "english",
+	"search.param_validation":     ParamValidationStrict,
+	"trash.retention":             7 * 24 * 60 * 60,
+	"trash.purge_interval":        60 * 60,
+	"expiry.reap_interval":        60,
+	"expiry.batch_size":           500,
+	"cache.enabled":               true,
+	"cache.size":                  10000,
+	"cache.ttl":                   60,
+	"tracing.exporter":            TracingExporterNone,
+	"tracing.service_name":        "string-analyzer",
+	"tracing.sample_ratio":        1.0,
+	"auth.enabled":                true,
+	"auth.public_metrics":         false,
+	"auth.public_docs":            false,
+	"auth.jwks_refresh_interval":  60 * 60,
+	"auth.jwt_subject_claim":      "sub",
+	"auth.jwt_scope_claim":        "scope",
+	"ratelimit.enabled":           true,
+	"ratelimit.rate":              10.0,
+	"ratelimit.burst":             20,
+	"ratelimit.routes":            []string{"GET /strings=2:10", "GET /strings/filter-by-natural-language=2:10"},
+	"ratelimit.quotas":            []string{"GET /strings/filter-by-natural-language=5000", "GET /admin/cache=1000"},
+	"ratelimit.auth_failures":     "0.1:10",
+	"value.max_bytes":             64 << 10,
+	"value.max_runes":             16 << 10,
+	"value.invalid_utf8":          PolicyReject,
+	"value.nul_bytes":             PolicyReject,
+	"value.control_characters":    PolicyReject,
+	"api.legacy_paths":            true,
+	"api.legacy_deprecated_at":    "2026-10-19T00:00:00Z",
+	"api.legacy_sunset_at":        "2027-04-19T00:00:00Z",
+}
+
+// splitList splits comma-separated entries, since a list read from a single
+// environment variable arrives as one string, and drops empty items.
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// parseRouteSettings splits "METHOD /pattern=value" entries into a map from
+// "METHOD /pattern" to value, parsing each value with parse.
+func parseRouteSettings[T any](entries []string, parse func(string) (T, error)) (map[string]T, error) {
+	settings := make(map[string]T, len(entries))
+	for _, entry := range entries {
+		route, value, ok := strings.Cut(entry, "=")
+		fields := strings.Fields(route)
+		if !ok || len(fields) != 2 || !strings.HasPrefix(fields[1], "/") {
+			return nil, fmt.Errorf("invalid route setting %q, want \"METHOD /pattern=value\"", entry)
+		}
+
+		parsed, err := parse(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid route setting %q: %w", entry, err)
+		}
+		settings[strings.ToUpper(fields[0])+" "+fields[1]] = parsed
+	}
+	return settings, nil
+}
+
+func parseLimit(value string) (Limit, error) {
+	rate, burst, ok := strings.Cut(value, ":")
+	if !ok {
+		return Limit{}, errors.New("want rate:burst")
+	}
+
+	var (
+		limit Limit
+		err   error
+	)
+	if limit.Rate, err = strconv.ParseFloat(rate, 64); err != nil || limit.Rate <= 0 {
+		return Limit{}, errors.New("rate must be a positive number")
+	}
+	if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst <= 0 {
+		return Limit{}, errors.New("burst must be a positive integer")
+	}
+	return limit, nil
+}
+
+func parseQuota(value string) (int, error) {
+	quota, err := strconv.Atoi(value)
+	if err != nil || quota <= 0 {
+		return 0, errors.New("quota must be a positive integer")
+	}
+	return quota, nil
 }
 
 func LoadConfig() (*Config, error) {
@@ -203,39 +541,72 @@ func LoadConfig() (*Config, error) {
 
 	k := koanf.New(".")
 
-	// Load DATABASE_* environment variables
-	err := k.Load(env.ProviderWithValue("DATABASE_", ".", func(key, value string) (string, any) {
-		// Transform DATABASE_HOST -> database.host
-		cleanKey := strings.TrimPrefix(key, "DATABASE_")
-		return "database." + strings.ToLower(cleanKey), value
-	}), nil)
-	if err != nil {
-		logger.Fatal().Err(err).Msg("could not load database env variables")
+	for key, value := range defaults {
+		if err := k.Set(key, value); err != nil {
+			logger.Fatal().Err(err).Str("key", key).Msg("could not set config default")
+		}
 	}
 
-	// Load SERVER_* environment variables
-	err = k.Load(env.ProviderWithValue("SERVER_", ".", func(key, value string) (string, any) {
-		// Transform SERVER_PORT -> server.port
-		cleanKey := strings.TrimPrefix(key, "SERVER_")
-		return "server." + strings.ToLower(cleanKey), value
-	}), nil)
-	if err != nil {
-		logger.Fatal().Err(err).Msg("could not load server env variables")
+	for prefix, section := range envSections {
+		err := k.Load(env.ProviderWithValue(prefix, ".", func(key, value string) (string, any) {
+			// Transform SERVER_PORT -> server.port
+			cleanKey := strings.TrimPrefix(key, prefix)
+			return section + "." + strings.ToLower(cleanKey), value
+		}), nil)
+		if err != nil {
+			logger.Fatal().Err(err).Str("prefix", prefix).Msg("could not load env variables")
+		}
 	}
 
 	mainConfig := &Config{}
 
-	err = k.Unmarshal("", mainConfig)
+	err := k.Unmarshal("", mainConfig)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("could not unmarshal main config")
 	}
 
+	for _, list := range []*[]string{
+		&mainConfig.Server.CORSAllowedOrigins,
+		&mainConfig.Server.CORSAllowedMethods,
+		&mainConfig.Server.CORSAllowedHeaders,
+		&mainConfig.Server.CORSExposedHeaders,
+		&mainConfig.RateLimit.Routes,
+		&mainConfig.RateLimit.Quotas,
+	} {
+		*list = splitList(*list)
+	}
+
+	mainConfig.RateLimit.RouteLimits, err = parseRouteSettings(mainConfig.RateLimit.Routes, parseLimit)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid RATELIMIT_ROUTES")
+	}
+	mainConfig.RateLimit.RouteQuotas, err = parseRouteSettings(mainConfig.RateLimit.Quotas, parseQuota)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid RATELIMIT_QUOTAS")
+	}
+	mainConfig.RateLimit.AuthFailureLimit, err = parseLimit(mainConfig.RateLimit.AuthFailures)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid RATELIMIT_AUTH_FAILURES")
+	}
+
+	mainConfig.API.LegacyDeprecation, err = time.Parse(time.RFC3339, mainConfig.API.LegacyDeprecatedAt)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid API_LEGACY_DEPRECATED_AT")
+	}
+	mainConfig.API.LegacySunset, err = time.Parse(time.RFC3339, mainConfig.API.LegacySunsetAt)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid API_LEGACY_SUNSET_AT")
+	}
+
 	validate := validator.New()
 
 	err = validate.Struct(mainConfig)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("config validation failed")
 	}
+	if mainConfig.Value.NULBytes == PolicyAllow && mainConfig.Database.Driver == DriverPostgres {
+		logger.Fatal().Msg("VALUE_NUL_BYTES=allow is not supported with the postgres driver, which cannot store NUL")
+	}
 	logger.Info().Msg("config validation passed")
 
 	return mainConfig, nil
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"net"
 	"net/url"
@@ -10,17 +11,29 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/tracing"
 	"github.com/rs/zerolog"
 )
 
+// Database holds the connection for the configured driver: Pool for
+// postgres, SQL for sqlite.
 type Database struct {
 	Pool *pgxpool.Pool
+	SQL  *sql.DB
 	log  *zerolog.Logger
 }
 
 const DatabasePingTimeout = 10
 
 func New(cfg *config.Config, logger *zerolog.Logger) (*Database, error) {
+	if cfg.Database.Driver == config.DriverSQLite {
+		return newSQLite(cfg, logger)
+	}
+
+	return newPostgres(cfg, logger)
+}
+
+func newPostgres(cfg *config.Config, logger *zerolog.Logger) (*Database, error) {
 	hostPort := net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port))
 
 	// URL-encode the password
@@ -37,6 +50,7 @@ func New(cfg *config.Config, logger *zerolog.Logger) (*Database, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse pgx pool config: %w", err)
 	}
+	pgxPoolConfig.ConnConfig.Tracer = tracing.PgxTracer{}
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), pgxPoolConfig)
 	if err != nil {
@@ -61,6 +75,10 @@ func New(cfg *config.Config, logger *zerolog.Logger) (*Database, error) {
 
 func (db *Database) Close() error {
 	db.log.Info().Msg("closing database connection pool")
+	if db.SQL != nil {
+		return db.SQL.Close()
+	}
+
 	db.Pool.Close()
 	return nil
 }
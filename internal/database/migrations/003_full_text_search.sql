@@ -0,0 +1,17 @@
+-- Write your migrate up statements here
+ALTER TABLE strings
+    ADD COLUMN search_vector TSVECTOR;
+
+UPDATE strings
+    SET search_vector = to_tsvector('{{.search_language}}'::regconfig, string_value);
+
+ALTER TABLE strings
+    ALTER COLUMN search_vector SET NOT NULL;
+
+CREATE INDEX strings_search_vector_idx ON strings USING GIN (search_vector);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS strings_search_vector_idx;
+
+ALTER TABLE strings DROP COLUMN search_vector;
@@ -0,0 +1,29 @@
+-- Write your migrate up statements here
+CREATE TABLE string_tags (
+    sha256_hash VARCHAR(64) NOT NULL REFERENCES strings (sha256_hash) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (sha256_hash, tag)
+);
+
+CREATE INDEX string_tags_tag_idx ON string_tags (tag);
+
+CREATE TABLE collections (
+    name TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE collection_strings (
+    collection_name TEXT NOT NULL REFERENCES collections (name) ON DELETE CASCADE,
+    sha256_hash VARCHAR(64) NOT NULL REFERENCES strings (sha256_hash) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (collection_name, sha256_hash)
+);
+
+CREATE INDEX collection_strings_sha256_hash_idx ON collection_strings (sha256_hash);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS collection_strings;
+DROP TABLE IF EXISTS collections;
+DROP TABLE IF EXISTS string_tags;
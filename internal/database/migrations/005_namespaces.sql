@@ -0,0 +1,54 @@
+-- Write your migrate up statements here
+-- Irreversible: collapsing namespaces back into one key space would drop
+-- values that exist in more than one namespace.
+CREATE TABLE namespaces (
+    name TEXT PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO namespaces (name) VALUES ('default');
+
+-- 1. Drop the link table constraints that point at the old single-column keys
+ALTER TABLE string_tags DROP CONSTRAINT string_tags_sha256_hash_fkey;
+ALTER TABLE collection_strings DROP CONSTRAINT collection_strings_sha256_hash_fkey;
+ALTER TABLE collection_strings DROP CONSTRAINT collection_strings_collection_name_fkey;
+
+-- 2. Scope strings by namespace; existing rows land in the default namespace
+ALTER TABLE strings
+    ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default'
+    REFERENCES namespaces (name) ON DELETE CASCADE;
+ALTER TABLE strings ALTER COLUMN namespace DROP DEFAULT;
+ALTER TABLE strings DROP CONSTRAINT strings_pkey;
+ALTER TABLE strings ADD PRIMARY KEY (namespace, sha256_hash);
+
+-- 3. Scope collections by namespace
+ALTER TABLE collections
+    ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default'
+    REFERENCES namespaces (name) ON DELETE CASCADE;
+ALTER TABLE collections ALTER COLUMN namespace DROP DEFAULT;
+ALTER TABLE collections DROP CONSTRAINT collections_pkey;
+ALTER TABLE collections ADD PRIMARY KEY (namespace, name);
+
+-- 4. Re-key the link tables on (namespace, ...)
+ALTER TABLE string_tags ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE string_tags ALTER COLUMN namespace DROP DEFAULT;
+ALTER TABLE string_tags DROP CONSTRAINT string_tags_pkey;
+ALTER TABLE string_tags ADD PRIMARY KEY (namespace, sha256_hash, tag);
+ALTER TABLE string_tags
+    ADD FOREIGN KEY (namespace, sha256_hash)
+    REFERENCES strings (namespace, sha256_hash) ON DELETE CASCADE;
+DROP INDEX string_tags_tag_idx;
+CREATE INDEX string_tags_tag_idx ON string_tags (namespace, tag);
+
+ALTER TABLE collection_strings ADD COLUMN namespace TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE collection_strings ALTER COLUMN namespace DROP DEFAULT;
+ALTER TABLE collection_strings DROP CONSTRAINT collection_strings_pkey;
+ALTER TABLE collection_strings ADD PRIMARY KEY (namespace, collection_name, sha256_hash);
+ALTER TABLE collection_strings
+    ADD FOREIGN KEY (namespace, collection_name)
+    REFERENCES collections (namespace, name) ON DELETE CASCADE;
+ALTER TABLE collection_strings
+    ADD FOREIGN KEY (namespace, sha256_hash)
+    REFERENCES strings (namespace, sha256_hash) ON DELETE CASCADE;
+DROP INDEX collection_strings_sha256_hash_idx;
+CREATE INDEX collection_strings_sha256_hash_idx ON collection_strings (namespace, sha256_hash);
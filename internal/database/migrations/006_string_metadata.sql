@@ -0,0 +1,11 @@
+-- Write your migrate up statements here
+ALTER TABLE strings
+    ADD COLUMN metadata JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX strings_metadata_idx ON strings USING GIN (metadata);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS strings_metadata_idx;
+
+ALTER TABLE strings DROP COLUMN metadata;
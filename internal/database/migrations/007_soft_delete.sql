@@ -0,0 +1,13 @@
+-- Write your migrate up statements here
+ALTER TABLE strings
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX strings_deleted_at_idx ON strings (deleted_at) WHERE deleted_at IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS strings_deleted_at_idx;
+
+DELETE FROM strings WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE strings DROP COLUMN deleted_at;
@@ -0,0 +1,32 @@
+-- Write your migrate up statements here
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    namespace TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    operation TEXT NOT NULL,
+    sha256_hash VARCHAR(64),
+    before JSONB,
+    after JSONB
+);
+
+CREATE INDEX audit_events_occurred_at_idx ON audit_events (occurred_at);
+CREATE INDEX audit_events_namespace_hash_idx ON audit_events (namespace, sha256_hash);
+CREATE INDEX audit_events_actor_idx ON audit_events (actor);
+
+-- Audit events are append-only
+CREATE FUNCTION audit_events_reject_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_reject_change();
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS audit_events;
+DROP FUNCTION IF EXISTS audit_events_reject_change();
@@ -0,0 +1,11 @@
+-- Write your migrate up statements here
+ALTER TABLE strings
+    ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX strings_expires_at_idx ON strings (expires_at) WHERE expires_at IS NOT NULL;
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS strings_expires_at_idx;
+
+ALTER TABLE strings DROP COLUMN expires_at;
@@ -0,0 +1,12 @@
+-- Write your migrate up statements here
+-- search_settings records the text search configuration search_vector was
+-- built with, so that a change of SEARCH_LANGUAGE re-indexes every row at
+-- startup. It starts empty, which forces one re-index of existing rows.
+CREATE TABLE search_settings (
+    singleton BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+    language TEXT NOT NULL
+);
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS search_settings;
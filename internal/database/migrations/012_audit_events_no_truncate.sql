@@ -0,0 +1,10 @@
+-- Write your migrate up statements here
+-- TRUNCATE does not fire the row-level triggers that keep audit_events
+-- append-only, so it is rejected by a statement-level one.
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_reject_change();
+
+---- create above / drop below ----
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
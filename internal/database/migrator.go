@@ -20,6 +20,10 @@ import (
 var migrations embed.FS
 
 func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) error {
+	if cfg.Database.Driver == config.DriverSQLite {
+		return migrateSQLite(ctx, logger, cfg)
+	}
+
 	hostPort := net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port))
 
 	// URL-encode the password
@@ -42,6 +46,8 @@ func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) er
 	if err != nil {
 		return fmt.Errorf("constructing database migrator: %w", err)
 	}
+	m.Data["search_language"] = cfg.Search.Language
+
 	subtree, err := fs.Sub(migrations, "migrations")
 	if err != nil {
 		return fmt.Errorf("retrieving database migrations subtree: %w", err)
@@ -61,5 +67,5 @@ func Migrate(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) er
 	} else {
 		logger.Info().Msgf("migrated database schema, from %d to %d", from, len(m.Migrations))
 	}
-	return nil
+	return syncSearchLanguage(ctx, logger, conn, cfg.Search.Language)
 }
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog"
+)
+
+// The full-text index is built for one search language. Stored strings are
+// indexed as they are inserted, so when SEARCH_LANGUAGE changes, the rows
+// already indexed no longer match new rows or queries. The functions below
+// run after migrating and rebuild the index for the configured language
+// when it differs from the one recorded in search_settings. Every replica
+// must be configured with the same language, or each restart rebuilds the
+// index again.
+
+// syncSearchLanguage re-indexes search_vector when language is not the one
+// it was built with.
+func syncSearchLanguage(ctx context.Context, logger *zerolog.Logger, conn *pgx.Conn, language string) error {
+	return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		// Replicas starting together wait here rather than re-indexing twice.
+		if _, err := tx.Exec(ctx, `LOCK TABLE search_settings IN EXCLUSIVE MODE`); err != nil {
+			return fmt.Errorf("locking search settings: %w", err)
+		}
+
+		var current string
+		err := tx.QueryRow(ctx, `SELECT language FROM search_settings`).Scan(&current)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("retrieving search language: %w", err)
+		}
+		if current == language {
+			return nil
+		}
+
+		tag, err := tx.Exec(ctx, `UPDATE strings SET search_vector = to_tsvector($1::regconfig, string_value)`, language)
+		if err != nil {
+			return fmt.Errorf("re-indexing strings for search language %q: %w", language, err)
+		}
+		if _, err = tx.Exec(ctx, `
+			INSERT INTO search_settings (language) VALUES ($1)
+			ON CONFLICT (singleton) DO UPDATE SET language = EXCLUDED.language
+		`, language); err != nil {
+			return fmt.Errorf("recording search language: %w", err)
+		}
+
+		logger.Info().Str("from", current).Str("to", language).Int64("rows", tag.RowsAffected()).Msg("re-indexed strings for the search language")
+		return nil
+	})
+}
+
+// syncSQLiteSearchLanguage rebuilds strings_fts with the tokenizer for
+// language when it was created for another. The triggers on strings refer
+// to strings_fts by name and keep working once it is recreated.
+func syncSQLiteSearchLanguage(ctx context.Context, logger *zerolog.Logger, db *sql.DB, language string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting search index rebuild: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current string
+	err = tx.QueryRowContext(ctx, `SELECT language FROM search_settings`).Scan(&current)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("retrieving search language: %w", err)
+	}
+	if current == language {
+		return nil
+	}
+
+	stmts := []string{
+		`DROP TABLE strings_fts`,
+		`CREATE VIRTUAL TABLE strings_fts USING fts5 (
+			string_value,
+			content = 'strings',
+			content_rowid = 'id',
+			tokenize = '` + sqliteTokenizer(language) + `'
+		)`,
+		`INSERT INTO strings_fts (strings_fts) VALUES ('rebuild')`,
+	}
+	for _, stmt := range stmts {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("rebuilding search index for search language %q: %w", language, err)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO search_settings (singleton, language) VALUES (1, ?)
+		ON CONFLICT (singleton) DO UPDATE SET language = excluded.language
+	`, language); err != nil {
+		return fmt.Errorf("recording search language: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("committing search index rebuild: %w", err)
+	}
+
+	logger.Info().Str("from", current).Str("to", language).Msg("rebuilt the search index for the search language")
+	return nil
+}
+
+// sqliteTokenizer is the FTS5 tokenizer for language: Porter stemming for
+// English and plain Unicode word splitting for everything else.
+func sqliteTokenizer(language string) string {
+	if language == "english" {
+		return "porter unicode61"
+	}
+	return "unicode61"
+}
@@ -0,0 +1,161 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"net/url"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+	"modernc.org/sqlite"
+)
+
+//go:embed sqlite_migrations/*.sql
+var sqliteMigrations embed.FS
+
+// SQLiteTimeFormat is how timestamps are stored in SQLite. It matches
+// strftime('%Y-%m-%dT%H:%M:%fZ') so stored values compare as strings.
+const SQLiteTimeFormat = "2006-01-02T15:04:05.000Z"
+
+func init() {
+	// json_contains(doc, sub) stands in for the Postgres jsonb @> operator.
+	sqlite.MustRegisterDeterministicScalarFunction("json_contains", 2, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		var doc, sub any
+		for i, target := range []*any{&doc, &sub} {
+			raw, ok := args[i].(string)
+			if !ok {
+				return nil, nil
+			}
+			if err := json.Unmarshal([]byte(raw), target); err != nil {
+				return nil, fmt.Errorf("json_contains: %w", err)
+			}
+		}
+
+		return util.JSONContains(doc, sub), nil
+	})
+
+	// row_digest(values...) stands in for hashing a row's text in Postgres.
+	// It is a 32-bit hash so that summing it over a result cannot overflow.
+	sqlite.MustRegisterDeterministicScalarFunction("row_digest", -1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		h := fnv.New32a()
+		for _, arg := range args {
+			fmt.Fprintf(h, "%v\x00", arg)
+		}
+		return int64(h.Sum32()), nil
+	})
+}
+
+func sqliteDSN(cfg *config.Config) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	// Writers take the lock up front so concurrent transactions wait on
+	// busy_timeout instead of failing when they try to upgrade.
+	params.Set("_txlock", "immediate")
+
+	return "file:" + cfg.Database.Path + "?" + params.Encode()
+}
+
+func newSQLite(cfg *config.Config, logger *zerolog.Logger) (*Database, error) {
+	db, err := sql.Open("sqlite", sqliteDSN(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DatabasePingTimeout*time.Second)
+	defer cancel()
+	if err = db.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	logger.Info().Str("path", cfg.Database.Path).Msg("connected to the database")
+
+	return &Database{
+		SQL: db,
+		log: logger,
+	}, nil
+}
+
+// migrateSQLite applies the embedded SQLite migrations that have not run yet,
+// each in its own transaction. Like tern, migrations are text/template files
+// and see the same template data.
+func migrateSQLite(ctx context.Context, logger *zerolog.Logger, cfg *config.Config) error {
+	db, err := sql.Open("sqlite", sqliteDSN(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	defer db.Close()
+
+	if _, err = db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`); err != nil {
+		return fmt.Errorf("creating schema_version table: %w", err)
+	}
+
+	var from int
+	if err = db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&from); err != nil {
+		return fmt.Errorf("retrieving current database migration version: %w", err)
+	}
+
+	names, err := fs.Glob(sqliteMigrations, "sqlite_migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("retrieving database migrations: %w", err)
+	}
+	sort.Strings(names)
+
+	data := map[string]any{
+		"search_language": cfg.Search.Language,
+	}
+
+	for version := from + 1; version <= len(names); version++ {
+		name := names[version-1]
+
+		tmpl, err := template.ParseFS(sqliteMigrations, name)
+		if err != nil {
+			return fmt.Errorf("loading database migration %s: %w", name, err)
+		}
+
+		var stmt bytes.Buffer
+		if err = tmpl.Execute(&stmt, data); err != nil {
+			return fmt.Errorf("rendering database migration %s: %w", name, err)
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("starting database migration %s: %w", name, err)
+		}
+
+		if _, err = tx.ExecContext(ctx, stmt.String()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("applying database migration %s: %w", name, err)
+		}
+		if _, err = tx.ExecContext(ctx, `DELETE FROM schema_version`); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("recording database migration %s: %w", name, err)
+		}
+		if _, err = tx.ExecContext(ctx, `INSERT INTO schema_version (version) VALUES (?)`, version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("recording database migration %s: %w", name, err)
+		}
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("committing database migration %s: %w", name, err)
+		}
+	}
+
+	if from == len(names) {
+		logger.Info().Msgf("database schema up to date, version %d", len(names))
+	} else {
+		logger.Info().Msgf("migrated database schema, from %d to %d", from, len(names))
+	}
+	return syncSQLiteSearchLanguage(ctx, logger, db, cfg.Search.Language)
+}
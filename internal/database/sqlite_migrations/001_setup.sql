@@ -0,0 +1,109 @@
+-- SQLite counterpart of the Postgres schema in ../migrations. Timestamps are
+-- stored as ISO 8601 UTC text with millisecond precision so they compare
+-- correctly as strings.
+CREATE TABLE namespaces (
+    name TEXT PRIMARY KEY,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+INSERT INTO namespaces (name) VALUES ('default');
+
+CREATE TABLE strings (
+    id INTEGER PRIMARY KEY,
+    namespace TEXT NOT NULL REFERENCES namespaces (name) ON DELETE CASCADE,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+
+    string_value TEXT NOT NULL,
+    -- Lowercased copy of string_value: SQLite's LIKE only folds ASCII, so
+    -- case-insensitive contains matches against this instead.
+    folded_value TEXT NOT NULL,
+    is_palindrome BOOLEAN NOT NULL,
+    unique_characters INTEGER NOT NULL,
+    word_count INTEGER NOT NULL,
+    sha256_hash TEXT NOT NULL,
+    length INTEGER NOT NULL,
+    metadata TEXT NOT NULL DEFAULT '{}' CHECK (json_type(metadata) = 'object'),
+    deleted_at TEXT,
+    expires_at TEXT,
+
+    UNIQUE (namespace, sha256_hash)
+);
+
+CREATE INDEX strings_deleted_at_idx ON strings (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX strings_expires_at_idx ON strings (expires_at) WHERE expires_at IS NOT NULL;
+
+-- Full-text index over string_value, kept in sync by the triggers below.
+-- Values are immutable once stored, so only inserts and deletes matter.
+CREATE VIRTUAL TABLE strings_fts USING fts5 (
+    string_value,
+    content = 'strings',
+    content_rowid = 'id',
+    tokenize = '{{if eq .search_language "english"}}porter {{end}}unicode61'
+);
+
+CREATE TRIGGER strings_fts_insert AFTER INSERT ON strings BEGIN
+    INSERT INTO strings_fts (rowid, string_value) VALUES (new.id, new.string_value);
+END;
+
+CREATE TRIGGER strings_fts_delete AFTER DELETE ON strings BEGIN
+    INSERT INTO strings_fts (strings_fts, rowid, string_value) VALUES ('delete', old.id, old.string_value);
+END;
+
+CREATE TABLE string_tags (
+    namespace TEXT NOT NULL,
+    sha256_hash TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (namespace, sha256_hash, tag),
+    FOREIGN KEY (namespace, sha256_hash)
+        REFERENCES strings (namespace, sha256_hash) ON DELETE CASCADE
+);
+
+CREATE INDEX string_tags_tag_idx ON string_tags (namespace, tag);
+
+CREATE TABLE collections (
+    namespace TEXT NOT NULL REFERENCES namespaces (name) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (namespace, name)
+);
+
+CREATE TABLE collection_strings (
+    namespace TEXT NOT NULL,
+    collection_name TEXT NOT NULL,
+    sha256_hash TEXT NOT NULL,
+    added_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (namespace, collection_name, sha256_hash),
+    FOREIGN KEY (namespace, collection_name)
+        REFERENCES collections (namespace, name) ON DELETE CASCADE,
+    FOREIGN KEY (namespace, sha256_hash)
+        REFERENCES strings (namespace, sha256_hash) ON DELETE CASCADE
+);
+
+CREATE INDEX collection_strings_sha256_hash_idx ON collection_strings (namespace, sha256_hash);
+
+CREATE TABLE audit_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    occurred_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    namespace TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    request_id TEXT,
+    operation TEXT NOT NULL,
+    sha256_hash TEXT,
+    before TEXT,
+    after TEXT
+);
+
+CREATE INDEX audit_events_occurred_at_idx ON audit_events (occurred_at);
+CREATE INDEX audit_events_namespace_hash_idx ON audit_events (namespace, sha256_hash);
+CREATE INDEX audit_events_actor_idx ON audit_events (actor);
+
+-- Audit events are append-only
+CREATE TRIGGER audit_events_reject_update BEFORE UPDATE ON audit_events BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
+
+CREATE TRIGGER audit_events_reject_delete BEFORE DELETE ON audit_events BEGIN
+    SELECT RAISE(ABORT, 'audit_events is append-only');
+END;
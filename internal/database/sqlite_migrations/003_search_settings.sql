@@ -0,0 +1,7 @@
+-- search_settings records the language strings_fts was tokenized for, so
+-- that a change of SEARCH_LANGUAGE rebuilds the index at startup. It starts
+-- empty, which forces one rebuild of the existing index.
+CREATE TABLE search_settings (
+    singleton INTEGER PRIMARY KEY DEFAULT 1 CHECK (singleton = 1),
+    language TEXT NOT NULL
+);
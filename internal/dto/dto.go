@@ -3,13 +3,17 @@ package dto
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/justinndidit/stringAnalyzer/internal/errs"
 )
 
 type UploadString struct {
-	Value string `json:"value"`
+	Value     string         `json:"value"`
+	Metadata  map[string]any `json:"metadata"`
+	TTL       *time.Duration `json:"ttl"`        // seconds, or a duration string such as "90m"
+	ExpiresAt *time.Time     `json:"expires_at"` // RFC 3339
 }
 
 func (u *UploadString) UnmarshalJSON(data []byte) error {
@@ -33,6 +37,48 @@ func (u *UploadString) UnmarshalJSON(data []byte) error {
 	}
 
 	u.Value = strVal
+
+	if meta, ok := raw["metadata"]; ok && meta != nil {
+		metaVal, ok := meta.(map[string]any)
+		if !ok {
+			return &errs.InvalidTypeError{
+				Field:    "metadata",
+				Expected: "object",
+				Got:      fmt.Sprintf("%T", meta),
+			}
+		}
+		u.Metadata = metaVal
+	}
+
+	if ttl, ok := raw["ttl"]; ok && ttl != nil {
+		var d time.Duration
+		switch v := ttl.(type) {
+		case float64:
+			d = time.Duration(v * float64(time.Second))
+		case string:
+			parsed, err := time.ParseDuration(v)
+			if err != nil {
+				return &errs.InvalidTypeError{Field: "ttl", Expected: "seconds or a duration such as \"90m\"", Got: v}
+			}
+			d = parsed
+		default:
+			return &errs.InvalidTypeError{Field: "ttl", Expected: "number or string", Got: fmt.Sprintf("%T", ttl)}
+		}
+		u.TTL = &d
+	}
+
+	if exp, ok := raw["expires_at"]; ok && exp != nil {
+		expStr, ok := exp.(string)
+		if !ok {
+			return &errs.InvalidTypeError{Field: "expires_at", Expected: "string", Got: fmt.Sprintf("%T", exp)}
+		}
+		t, err := time.Parse(time.RFC3339, expStr)
+		if err != nil {
+			return &errs.InvalidTypeError{Field: "expires_at", Expected: "an RFC 3339 timestamp", Got: expStr}
+		}
+		u.ExpiresAt = &t
+	}
+
 	return nil
 }
 
@@ -43,14 +89,21 @@ type CreateString struct {
 	WordCount        int
 	Hash             string
 	Length           int
+	Metadata         map[string]any
+	ExpiresAt        *time.Time
 }
 
 type QueryParams struct {
-	IsPalindrome      *bool  `validate:"omitempty"` // optional, pointer differentiates false vs not provided
-	MinLength         *int   `validate:"omitempty,gte=0"`
-	MaxLength         *int   `validate:"omitempty,gte=0"`
-	WordCount         *int   `validate:"omitempty,gte=0"`
-	ContainsCharacter string `validate:"omitempty,len=1"` // optional, must be 1 char if provided
+	IsPalindrome      *bool          `query:"is_palindrome" validate:"omitempty"` // optional, pointer differentiates false vs not provided
+	MinLength         *int           `query:"min_length" validate:"omitempty,gte=0"`
+	MaxLength         *int           `query:"max_length" validate:"omitempty,gte=0"`
+	WordCount         *int           `query:"word_count" validate:"omitempty,gte=0"`
+	ContainsCharacter string         `query:"contains_character" validate:"omitempty,len=1"`   // optional, must be 1 char if provided
+	Query             string         `query:"q" validate:"omitempty,max=1000"`                 // optional full-text search terms
+	Tags              []string       `query:"tag" validate:"omitempty,dive,required,max=64"`   // optional, strings must carry every tag
+	Collection        string         `query:"collection" validate:"omitempty,max=100"`         // optional collection name
+	Metadata          map[string]any `query:"metadata" validate:"omitempty"`                   // optional, metadata must contain these key/value pairs
+	MetadataKeys      []string       `query:"has_metadata" validate:"omitempty,dive,required"` // optional, metadata must contain these keys
 }
 
 func (q *QueryParams) Validate() error {
@@ -58,6 +111,50 @@ func (q *QueryParams) Validate() error {
 	return validate.Struct(q)
 }
 
+// PatchString is a JSON Merge Patch (RFC 7396) document for a stored string.
+// Only metadata can change; every other field is derived from the value.
+type PatchString struct {
+	Metadata any `json:"metadata"`
+}
+
+type AddTags struct {
+	Tags []string `json:"tags" validate:"required,min=1,dive,required,max=64"`
+}
+
+type CreateCollection struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type CreateNamespace struct {
+	Name string `json:"name" validate:"required,max=63"`
+}
+
+// CreateAPIKey is the request body of POST /admin/keys.
+type CreateAPIKey struct {
+	Name   string   `json:"name" validate:"required,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=read write delete admin"`
+}
+
+// StoreAPIKey is what the store persists for a new API key.
+type StoreAPIKey struct {
+	Name   string
+	Prefix string
+	Hash   string
+	Scopes []string
+}
+
+type AuditQuery struct {
+	Namespace string     `query:"namespace"`
+	Actor     string     `query:"actor"`
+	Operation string     `query:"operation" validate:"omitempty,oneof=create update delete restore purge expire delete_namespace"`
+	StringID  string     `query:"string_id" validate:"omitempty,len=64,hexadecimal"`
+	RequestID string     `query:"request_id"`
+	Since     *time.Time `query:"since"`
+	Until     *time.Time `query:"until"`
+	Cursor    *int64     `query:"cursor" validate:"omitempty,gt=0"`
+	Limit     int        `query:"limit" validate:"gte=1,lte=500"`
+}
+
 // NLP
 type FilterParams struct {
 	IsPalindrome      *bool
@@ -16,3 +16,27 @@ func (e *InvalidTypeError) Error() string {
 }
 
 var ErrNotFound = errors.New("string not found")
+
+var ErrCollectionNotFound = errors.New("collection not found")
+
+var ErrAlreadyExists = errors.New("resource already exists")
+
+var ErrNamespaceNotFound = errors.New("namespace not found")
+
+var ErrDefaultNamespace = errors.New("the default namespace cannot be deleted")
+
+var ErrAPIKeyNotFound = errors.New("api key not found")
+
+var ErrValueTooLarge = errors.New("value exceeds the maximum length")
+
+var ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+var ErrNULByte = errors.New("value contains a NUL byte")
+
+var ErrControlCharacter = errors.New("value contains a control character")
+
+var ErrUnknownFormat = errors.New("unknown response format")
+
+var ErrNotAcceptable = errors.New("no acceptable response format")
+
+var ErrPreconditionFailed = errors.New("precondition failed")
@@ -0,0 +1,141 @@
+package errs
+
+import "net/http"
+
+// ProblemContentType is the media type of Problem responses.
+const ProblemContentType = "application/problem+json"
+
+// Code is a stable, machine-readable identifier for a kind of error. Clients
+// should branch on it rather than on Detail, which is meant for people and
+// may change.
+type Code string
+
+const (
+	CodeInvalidBody          Code = "invalid_body"
+	CodeInvalidType          Code = "invalid_type"
+	CodeValidationFailed     Code = "validation_failed"
+	CodeInvalidQuery         Code = "invalid_query"
+	CodeConflictingFilters   Code = "conflicting_filters"
+	CodeInvalidContent       Code = "invalid_content"
+	CodeImmutableField       Code = "immutable_field"
+	CodeBodyTooLarge         Code = "body_too_large"
+	CodeValueTooLarge        Code = "value_too_large"
+	CodeUnsupportedMediaType Code = "unsupported_media_type"
+	CodeNotAcceptable        Code = "not_acceptable"
+
+	CodeUnauthorized      Code = "unauthorized"
+	CodeInsufficientScope Code = "insufficient_scope"
+	CodeRateLimited       Code = "rate_limited"
+	CodeQuotaExceeded     Code = "quota_exceeded"
+
+	CodeStringNotFound     Code = "string_not_found"
+	CodeCollectionNotFound Code = "collection_not_found"
+	CodeNamespaceNotFound  Code = "namespace_not_found"
+	CodeAPIKeyNotFound     Code = "api_key_not_found"
+	CodeNotInTrash         Code = "not_in_trash"
+	CodeNotInCollection    Code = "not_in_collection"
+	CodeTagNotFound        Code = "tag_not_found"
+
+	CodeStringExists     Code = "string_already_exists"
+	CodeCollectionExists Code = "collection_already_exists"
+	CodeNamespaceExists  Code = "namespace_already_exists"
+	CodeDefaultNamespace Code = "default_namespace_protected"
+
+	CodePreconditionFailed Code = "precondition_failed"
+
+	CodeInternal Code = "internal_error"
+)
+
+// statuses maps every Code to the HTTP status it is served with.
+var statuses = map[Code]int{
+	CodeInvalidBody:          http.StatusBadRequest,
+	CodeInvalidType:          http.StatusUnprocessableEntity,
+	CodeValidationFailed:     http.StatusBadRequest,
+	CodeInvalidQuery:         http.StatusBadRequest,
+	CodeConflictingFilters:   http.StatusUnprocessableEntity,
+	CodeInvalidContent:       http.StatusUnprocessableEntity,
+	CodeImmutableField:       http.StatusUnprocessableEntity,
+	CodeBodyTooLarge:         http.StatusRequestEntityTooLarge,
+	CodeValueTooLarge:        http.StatusRequestEntityTooLarge,
+	CodeUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	CodeNotAcceptable:        http.StatusNotAcceptable,
+
+	CodeUnauthorized:      http.StatusUnauthorized,
+	CodeInsufficientScope: http.StatusForbidden,
+	CodeRateLimited:       http.StatusTooManyRequests,
+	CodeQuotaExceeded:     http.StatusTooManyRequests,
+
+	CodeStringNotFound:     http.StatusNotFound,
+	CodeCollectionNotFound: http.StatusNotFound,
+	CodeNamespaceNotFound:  http.StatusNotFound,
+	CodeAPIKeyNotFound:     http.StatusNotFound,
+	CodeNotInTrash:         http.StatusNotFound,
+	CodeNotInCollection:    http.StatusNotFound,
+	CodeTagNotFound:        http.StatusNotFound,
+
+	CodeStringExists:     http.StatusConflict,
+	CodeCollectionExists: http.StatusConflict,
+	CodeNamespaceExists:  http.StatusConflict,
+	CodeDefaultNamespace: http.StatusConflict,
+
+	CodePreconditionFailed: http.StatusPreconditionFailed,
+
+	CodeInternal: http.StatusInternalServerError,
+}
+
+// Status returns the HTTP status c is served with.
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// FieldError describes one invalid field of a request body or one invalid
+// query parameter.
+type FieldError struct {
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+// Problem is an RFC 7807 problem details object. Type is derived from Code,
+// which is also included as an extension member along with any per-field
+// Errors and the ID of the request that failed.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// NewProblem builds the Problem for code with a human-readable detail.
+func NewProblem(code Code, detail string) *Problem {
+	status := code.Status()
+	return &Problem{
+		Type:   "urn:stringanalyzer:problem:" + string(code),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Internal is the Problem served for unexpected failures. Their cause is
+// logged, never returned.
+func Internal() *Problem {
+	return NewProblem(CodeInternal, "Something went wrong")
+}
+
+// WithErrors attaches field-level errors to p.
+func (p *Problem) WithErrors(errors ...FieldError) *Problem {
+	p.Errors = append(p.Errors, errors...)
+	return p
+}
+
+func (p *Problem) Error() string {
+	return string(p.Code) + ": " + p.Detail
+}
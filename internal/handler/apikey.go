@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/auth"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+func (s *StringAnalyzerHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "ListAPIKeys")
+	defer span.End()
+
+	keys, err := s.keys.ListAPIKeys(r.Context())
+	if err != nil {
+		s.log(r).Error().Err(err).Msg("error listing api keys")
+		util.WriteProblem(w, r, errs.Internal())
+		return
+	}
+
+	data := make([]map[string]any, 0, len(keys))
+	for i := range keys {
+		data = append(data, apiKeyView(r, &keys[i]))
+	}
+
+	rb := &util.Envelope{
+		"count": len(data),
+		"data":  data,
+	}
+	util.WriteJson(w, http.StatusOK, *rb)
+}
+
+// CreateAPIKey issues a key. The response is the only time the key itself is
+// returned.
+func (s *StringAnalyzerHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "CreateAPIKey")
+	defer span.End()
+
+	var body dto.CreateAPIKey
+	defer r.Body.Close()
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		if writeBodyTooLarge(w, r, err) {
+			return
+		}
+		s.log(r).Error().Err(err).Msg("error decoding create api key body")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidBody, "Invalid request body or missing \"name\" field"))
+		return
+	}
+
+	if err := validate.Struct(body); err != nil {
+		util.WriteProblem(w, r, validationProblem(errs.CodeValidationFailed, "\"name\" is required and \"scopes\" must list at least one of read, write, delete, admin", err))
+		return
+	}
+
+	key, record, err := auth.IssueKey(r.Context(), s.keys, body.Name, body.Scopes)
+	if err != nil {
+		s.log(r).Error().Err(err).Msg("error creating api key")
+		util.WriteProblem(w, r, errs.Internal())
+		return
+	}
+
+	view := apiKeyView(r, record)
+	view["key"] = key
+	util.WriteJson(w, http.StatusCreated, view)
+}
+
+func (s *StringAnalyzerHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "RevokeAPIKey")
+	defer span.End()
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeAPIKeyNotFound, "API key does not exist in the system"))
+		return
+	}
+
+	if err := s.keys.RevokeAPIKey(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrAPIKeyNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeAPIKeyNotFound, "API key does not exist in the system"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error revoking api key")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/metrics"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+const (
+	defaultAuditPageSize = 50
+	maxAuditPageSize     = 500
+)
+
+func (s *StringAnalyzerHandler) ListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "ListAuditEvents")
+	defer span.End()
+
+	query := r.URL.Query()
+
+	params := dto.AuditQuery{
+		Namespace: query.Get("namespace"),
+		Actor:     query.Get("actor"),
+		Operation: query.Get("operation"),
+		StringID:  query.Get("string_id"),
+		RequestID: query.Get("request_id"),
+		Limit:     defaultAuditPageSize,
+	}
+
+	// Parse since / until (RFC 3339)
+	for name, target := range map[string]**time.Time{"since": &params.Since, "until": &params.Until} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Invalid query parameters").WithErrors(errs.FieldError{Field: name, Detail: "must be an RFC 3339 timestamp"}))
+			return
+		}
+		*target = &t
+	}
+
+	// Parse cursor
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Invalid query parameters").WithErrors(errs.FieldError{Field: "cursor", Detail: "must be an integer"}))
+			return
+		}
+		params.Cursor = &cursor
+	}
+
+	// Parse limit
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Invalid query parameters").WithErrors(errs.FieldError{Field: "limit", Detail: "must be an integer"}))
+			return
+		}
+		params.Limit = limit
+	}
+
+	if err := validate.Struct(params); err != nil {
+		s.log(r).Error().Err(err).Msg("error validating audit params")
+		util.WriteProblem(w, r, validationProblem(errs.CodeInvalidQuery, "Invalid query parameters", err))
+		return
+	}
+
+	events, err := s.audit.ListAuditEvents(r.Context(), params)
+	if err != nil {
+		s.log(r).Error().Err(err).Msg("error listing audit events")
+		util.WriteProblem(w, r, errs.Internal())
+		return
+	}
+
+	metrics.ObserveRows("audit", len(events))
+
+	var nextCursor any
+	if len(events) == params.Limit {
+		nextCursor = strconv.FormatInt(events[len(events)-1].ID, 10)
+	}
+
+	data := make([]map[string]any, 0, len(events))
+	for i := range events {
+		data = append(data, auditEventView(r, &events[i]))
+	}
+
+	name := fieldNamer(r)
+	rb := &util.Envelope{
+		"count":             len(data),
+		"data":              data,
+		name("next_cursor"): nextCursor,
+	}
+	util.WriteJson(w, http.StatusOK, *rb)
+}
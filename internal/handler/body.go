@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+// writeBodyTooLarge answers 413 and returns true when err comes from reading
+// past the body size limit set by middleware.MaxBodySize.
+func writeBodyTooLarge(w http.ResponseWriter, r *http.Request, err error) bool {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return false
+	}
+
+	util.WriteProblem(w, r, errs.NewProblem(errs.CodeBodyTooLarge, fmt.Sprintf("Request body exceeds the %d byte limit", maxErr.Limit)))
+	return true
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/justinndidit/stringAnalyzer/internal/render"
+)
+
+func (s *StringAnalyzerHandler) CacheStats(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "CacheStats")
+	defer span.End()
+
+	format, ok := negotiate(w, r)
+	if !ok {
+		return
+	}
+
+	if s.cache == nil {
+		if notModified(w, r, weakETag(r, format, false)) {
+			return
+		}
+		render.Write(w, format, http.StatusOK, map[string]any{"enabled": false}, cacheStatsColumns(r))
+		return
+	}
+
+	stats := s.cache.Stats()
+
+	hitRatio := 0.0
+	if total := stats.Hits + stats.Misses; total > 0 {
+		hitRatio = float64(stats.Hits) / float64(total)
+	}
+
+	name := fieldNamer(r)
+	view := map[string]any{
+		"enabled":         true,
+		"hits":            stats.Hits,
+		"misses":          stats.Misses,
+		name("hit_ratio"): hitRatio,
+		"evictions":       stats.Evictions,
+		"entries":         stats.Entries,
+		"capacity":        stats.Capacity,
+	}
+	if notModified(w, r, weakETag(r, format, stats)) {
+		return
+	}
+	if err := render.Write(w, format, http.StatusOK, view, cacheStatsColumns(r)); err != nil {
+		s.log(r).Error().Err(err).Msg("error writing cache stats")
+	}
+}
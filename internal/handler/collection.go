@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+func (s *StringAnalyzerHandler) ListCollections(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "ListCollections")
+	defer span.End()
+
+	collections, err := s.repo.ListCollections(r.Context(), namespaceFrom(r.Context()))
+	if err != nil {
+		s.log(r).Error().Err(err).Msg("error listing collections")
+		util.WriteProblem(w, r, errs.Internal())
+		return
+	}
+
+	data := make([]map[string]any, 0, len(collections))
+	for i := range collections {
+		data = append(data, collectionView(r, &collections[i]))
+	}
+
+	rb := &util.Envelope{
+		"count": len(data),
+		"data":  data,
+	}
+	util.WriteJson(w, http.StatusOK, *rb)
+}
+
+func (s *StringAnalyzerHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "CreateCollection")
+	defer span.End()
+
+	var body dto.CreateCollection
+	defer r.Body.Close()
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		if writeBodyTooLarge(w, r, err) {
+			return
+		}
+		s.log(r).Error().Err(err).Msg("error decoding create collection body")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidBody, "Invalid request body or missing \"name\" field"))
+		return
+	}
+
+	body.Name = strings.TrimSpace(body.Name)
+	if err := validate.Struct(body); err != nil {
+		s.log(r).Error().Err(err).Msg("error validating collection")
+		util.WriteProblem(w, r, validationProblem(errs.CodeValidationFailed, "\"name\" is required and must be at most 100 characters", err))
+		return
+	}
+
+	collection, err := s.repo.CreateCollection(r.Context(), namespaceFrom(r.Context()), body.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrAlreadyExists):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeCollectionExists, "Collection already exists in the system"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error creating collection")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	util.WriteJson(w, http.StatusCreated, collectionView(r, collection))
+}
+
+func (s *StringAnalyzerHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "DeleteCollection")
+	defer span.End()
+
+	name := chi.URLParam(r, "name")
+
+	if err := s.repo.DeleteCollection(r.Context(), namespaceFrom(r.Context()), name); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCollectionNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeCollectionNotFound, "Collection does not exist in the system"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error deleting collection")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *StringAnalyzerHandler) AddToCollection(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "AddToCollection")
+	defer span.End()
+
+	name := chi.URLParam(r, "name")
+	param := chi.URLParam(r, "string_value")
+
+	if err := s.repo.AddToCollection(r.Context(), namespaceFrom(r.Context()), name, util.Hash(param)); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrCollectionNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeCollectionNotFound, "Collection does not exist in the system"))
+
+		case errors.Is(err, errs.ErrNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeStringNotFound, "String does not exist in the system"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error adding string to collection")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *StringAnalyzerHandler) RemoveFromCollection(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "RemoveFromCollection")
+	defer span.End()
+
+	name := chi.URLParam(r, "name")
+	param := chi.URLParam(r, "string_value")
+
+	if err := s.repo.RemoveFromCollection(r.Context(), namespaceFrom(r.Context()), name, util.Hash(param)); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeNotInCollection, "String is not part of this collection"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error removing string from collection")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
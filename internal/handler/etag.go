@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/render"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+// A string's computed properties follow from its value, so its sha256_hash
+// and the analyzer version pin them down. Its ETag adds a digest of what can
+// change without the value changing, and of how the request renders it.
+
+// stringETag is the strong ETag of record as r renders it with fields.
+func stringETag(r *http.Request, record *model.String, fields fieldSet) string {
+	selected := make([]string, 0, len(fields))
+	for name := range fields {
+		selected = append(selected, name)
+	}
+	sort.Strings(selected)
+
+	return fmt.Sprintf(`"%s-a%d-%s"`, record.Hash, util.AnalyzerVersion, digest(
+		versionFrom(r.Context()),
+		selected,
+		record.CreatedAt,
+		record.Tags,
+		record.Collections,
+		record.Metadata,
+		record.ExpiresAt,
+	))
+}
+
+// weakETag is a weak ETag for a list or statistics response to r built from
+// parts, which must include everything the response is rendered from
+// besides the request itself, and the negotiated format.
+func weakETag(r *http.Request, format render.Format, parts ...any) string {
+	parts = append(parts, util.AnalyzerVersion, versionFrom(r.Context()), namespaceFrom(r.Context()), r.URL.RawQuery, format)
+	return `W/"` + digest(parts...) + `"`
+}
+
+// digest hashes the JSON encoding of parts.
+func digest(parts ...any) string {
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, part := range parts {
+		// Everything hashed here was read from JSON or the database, so it
+		// encodes.
+		_ = enc.Encode(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// notModified sets the ETag of the response to r and, when If-None-Match
+// matches it, writes 304 Not Modified and returns true.
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+
+	if !etagMatches(r.Header.Get("If-None-Match"), etag, true) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// ifMatch returns the precondition r's If-Match header sets on the string
+// it changes, or nil when it has none. If-Match uses strong comparison, so
+// only the ETag of the full representation at r's version matches.
+func ifMatch(r *http.Request) repository.Precondition {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return nil
+	}
+
+	return func(current *model.String) error {
+		if !etagMatches(header, stringETag(r, current, allFields), false) {
+			return errs.ErrPreconditionFailed
+		}
+		return nil
+	}
+}
+
+// failedIfMatch reports whether err from a write guarded by ifMatch means
+// the request's If-Match did not match, which includes the string having
+// gone.
+func failedIfMatch(r *http.Request, err error) bool {
+	if errors.Is(err, errs.ErrPreconditionFailed) {
+		return true
+	}
+	return errors.Is(err, errs.ErrNotFound) && r.Header.Get("If-Match") != ""
+}
+
+// etagMatches reports whether the If-Match or If-None-Match header lists
+// etag or is "*". Weak comparison ignores the W/ prefix.
+func etagMatches(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+			etag = strings.TrimPrefix(etag, "W/")
+		} else if strings.HasPrefix(candidate, "W/") || strings.HasPrefix(etag, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
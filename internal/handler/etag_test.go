@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+)
+
+func TestETagMatches(t *testing.T) {
+	const strong, weak = `"abc"`, `W/"abc"`
+
+	for _, tc := range []struct {
+		header string
+		etag   string
+		weak   bool
+		want   bool
+	}{
+		{``, strong, true, false},
+		{`*`, strong, false, true},
+		{` * `, weak, true, true},
+		{`"abc"`, strong, false, true},
+		{`"abd"`, strong, false, false},
+		{`abc`, strong, false, false},
+		{`"x", "abc"`, strong, false, true},
+		{`"x",W/"abc"`, strong, true, true},
+
+		// Weak comparison ignores W/ on either side.
+		{`W/"abc"`, strong, true, true},
+		{`"abc"`, weak, true, true},
+		{`W/"abc"`, weak, true, true},
+
+		// Strong comparison never matches a weak ETag.
+		{`W/"abc"`, strong, false, false},
+		{`"abc"`, weak, false, false},
+		{`W/"abc"`, weak, false, false},
+		{`W/"abc", "abc"`, strong, false, true},
+	} {
+		if got := etagMatches(tc.header, tc.etag, tc.weak); got != tc.want {
+			t.Errorf("etagMatches(%q, %q, weak %v) = %v, want %v", tc.header, tc.etag, tc.weak, got, tc.want)
+		}
+	}
+}
+
+func TestListETagFollowsTheResult(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationStrict)
+	create(t, server, "racecar")
+	create(t, server, "hello world")
+
+	resp, body := do(t, server, http.MethodGet, "/strings?is_palindrome=true", "")
+	expectStatus(t, resp, body, http.StatusOK)
+	etag := resp.Header.Get("ETag")
+	if !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("ETag = %q, want a weak ETag", etag)
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/strings?is_palindrome=true", "", "If-None-Match", etag)
+	expectStatus(t, resp, body, http.StatusNotModified)
+
+	// A string outside the result leaves it unchanged, one inside does not.
+	create(t, server, "sunny day")
+	resp, body = do(t, server, http.MethodGet, "/strings?is_palindrome=true", "", "If-None-Match", etag)
+	expectStatus(t, resp, body, http.StatusNotModified)
+
+	create(t, server, "level")
+	resp, body = do(t, server, http.MethodGet, "/strings?is_palindrome=true", "", "If-None-Match", etag)
+	expectStatus(t, resp, body, http.StatusOK)
+	if body["count"] != float64(2) {
+		t.Errorf("count = %v, want 2", body["count"])
+	}
+
+	// The ETag also depends on how the result is rendered.
+	resp, body = do(t, server, http.MethodGet, "/strings?is_palindrome=true&fields=value", "", "If-None-Match", resp.Header.Get("ETag"))
+	expectStatus(t, resp, body, http.StatusOK)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+)
+
+// fieldSet holds the fields a client selected with the fields parameter,
+// keyed by their camelCase names. The nil fieldSet selects every field.
+type fieldSet map[string]bool
+
+// allFields is the fieldSet of requests that cannot select fields.
+var allFields fieldSet
+
+// stringFields and stringProperties are what fields can select from a
+// string. "properties" selects every property, as does leaving fields out.
+var (
+	stringFields     = []string{"id", "value", "namespace", "properties", "tags", "collections", "metadata", "expires_at", "created_at", "rank", "snippet"}
+	stringProperties = []string{"length", "is_palindrome", "unique_characters", "word_count", "sha256_hash", "character_frequency_map"}
+
+	selectable = func() map[string]bool {
+		names := map[string]bool{}
+		for _, list := range [][]string{stringFields, stringProperties} {
+			for _, name := range list {
+				names[camelCase(name)] = true
+			}
+		}
+		return names
+	}()
+)
+
+// parseFields reads the comma-separated fields parameter. Names may be
+// given in snake_case or camelCase whatever the API version. It returns the
+// known names, or nil when there are none, along with an error for each
+// unknown one.
+func parseFields(query url.Values) (fieldSet, []errs.FieldError) {
+	var (
+		fields    fieldSet
+		fieldErrs []errs.FieldError
+	)
+	for _, value := range query["fields"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if !selectable[camelCase(name)] {
+				fieldErrs = append(fieldErrs, errs.FieldError{Field: "fields", Detail: fmt.Sprintf("%q is not a field of a string", name)})
+				continue
+			}
+			if fields == nil {
+				fields = fieldSet{}
+			}
+			fields[camelCase(name)] = true
+		}
+	}
+	return fields, fieldErrs
+}
+
+// has reports whether the top-level field name is selected.
+func (f fieldSet) has(name string) bool {
+	return f == nil || f[camelCase(name)]
+}
+
+// hasProperty reports whether the property name is selected, by itself or
+// through "properties". Callers should skip computing properties that are
+// not.
+func (f fieldSet) hasProperty(name string) bool {
+	return f == nil || f["properties"] || f[camelCase(name)]
+}
+
+// selection is what the store needs to compute for the selected fields.
+// Snippets are only built for searches.
+func (f fieldSet) selection(search bool) repository.Selection {
+	return repository.Selection{
+		Tags:        f.has("tags"),
+		Collections: f.has("collections"),
+		Snippet:     search && f.has("snippet"),
+	}
+}
+
+// columns keeps the CSV columns whose values are selected.
+func (f fieldSet) columns(columns []string) []string {
+	if f == nil {
+		return columns
+	}
+
+	var selected []string
+	for _, column := range columns {
+		if property, ok := strings.CutPrefix(column, "properties."); ok {
+			if f.hasProperty(property) {
+				selected = append(selected, column)
+			}
+			continue
+		}
+		// v1 natural language results keep properties at the top level.
+		if f.has(column) || f.hasProperty(column) {
+			selected = append(selected, column)
+		}
+	}
+	return selected
+}
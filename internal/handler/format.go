@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/metrics"
+	"github.com/justinndidit/stringAnalyzer/internal/render"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+)
+
+// negotiate picks the format to answer r in. When there is none it writes
+// the problem and returns false.
+func negotiate(w http.ResponseWriter, r *http.Request) (render.Format, bool) {
+	format, err := render.Negotiate(r)
+	switch {
+	case errors.Is(err, errs.ErrUnknownFormat):
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Invalid query parameters").WithErrors(errs.FieldError{Field: "format", Detail: "must be one of: json, csv, ndjson, msgpack"}))
+		return "", false
+
+	case errors.Is(err, errs.ErrNotAcceptable):
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeNotAcceptable, "This resource is available as application/json, text/csv, application/x-ndjson and application/msgpack"))
+		return "", false
+	}
+	return format, true
+}
+
+// errNotModified stops a streamed query once its response turns out to be
+// 304 Not Modified.
+var errNotModified = errors.New("not modified")
+
+// listStream writes the list response to r from a store query that streams
+// its results. The summary the query reports first is enough for the ETag
+// and the list's count, so rows are written as they are read.
+type listStream struct {
+	w      http.ResponseWriter
+	r      *http.Request
+	format render.Format
+	header render.ListHeader
+
+	// query labels the rows metric.
+	query string
+	list  *render.List
+}
+
+// start is the query's start callback.
+func (l *listStream) start(summary repository.ResultSummary) error {
+	metrics.ObserveRows(l.query, summary.Count)
+	if notModified(l.w, l.r, weakETag(l.r, l.format, summary)) {
+		return errNotModified
+	}
+
+	l.header.Count = summary.Count
+	l.list = render.StartList(l.w, l.format, http.StatusOK, l.header)
+	return nil
+}
+
+// finish completes the response once the query returned err. A failure
+// before the list started is reported as a problem; after that the status
+// has been sent, so it can only be logged and the client sees a truncated
+// body.
+func (l *listStream) finish(logger *zerolog.Logger, err error) {
+	switch {
+	case errors.Is(err, errNotModified):
+
+	case err != nil && l.list == nil:
+		logger.Error().Err(err).Str("query", l.query).Msg("error fetching records")
+		util.WriteProblem(l.w, l.r, errs.Internal())
+
+	case err != nil:
+		logger.Error().Err(err).Str("query", l.query).Msg("error streaming records")
+
+	default:
+		if err := l.list.End(); err != nil {
+			logger.Error().Err(err).Str("query", l.query).Msg("error writing records")
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+// NamespaceHeader selects the namespace a request operates on. Requests
+// without it use repository.DefaultNamespace.
+const NamespaceHeader = "X-Namespace"
+
+type namespaceContextKey struct{}
+
+// namespaceFrom returns the namespace resolved by ResolveNamespace.
+func namespaceFrom(ctx context.Context) string {
+	if namespace, ok := ctx.Value(namespaceContextKey{}).(string); ok {
+		return namespace
+	}
+	return repository.DefaultNamespace
+}
+
+// ResolveNamespace reads the namespace from the request, makes sure it exists
+// and stores it in the request context for the string handlers.
+func (s *StringAnalyzerHandler) ResolveNamespace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespace := r.Header.Get(NamespaceHeader)
+		if namespace == "" {
+			namespace = repository.DefaultNamespace
+		}
+
+		if !util.IsValidNamespace(namespace) {
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeValidationFailed, "Invalid namespace name").WithErrors(errs.FieldError{Field: NamespaceHeader, Detail: "must be 1-63 lowercase letters, digits, '-' or '_'"}))
+			return
+		}
+
+		exists, err := s.namespaces.NamespaceExists(r.Context(), namespace)
+		if err != nil {
+			s.log(r).Error().Err(err).Str("namespace", namespace).Msg("error resolving namespace")
+			util.WriteProblem(w, r, errs.Internal())
+			return
+		}
+
+		if !exists {
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeNamespaceNotFound, "Namespace does not exist in the system"))
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), namespaceContextKey{}, namespace)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (s *StringAnalyzerHandler) ListNamespaces(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "ListNamespaces")
+	defer span.End()
+
+	namespaces, err := s.namespaces.ListNamespaces(r.Context())
+	if err != nil {
+		s.log(r).Error().Err(err).Msg("error listing namespaces")
+		util.WriteProblem(w, r, errs.Internal())
+		return
+	}
+
+	data := make([]map[string]any, 0, len(namespaces))
+	for i := range namespaces {
+		data = append(data, namespaceView(r, &namespaces[i]))
+	}
+
+	rb := &util.Envelope{
+		"count": len(data),
+		"data":  data,
+	}
+	util.WriteJson(w, http.StatusOK, *rb)
+}
+
+func (s *StringAnalyzerHandler) CreateNamespace(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "CreateNamespace")
+	defer span.End()
+
+	var body dto.CreateNamespace
+	defer r.Body.Close()
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		if writeBodyTooLarge(w, r, err) {
+			return
+		}
+		s.log(r).Error().Err(err).Msg("error decoding create namespace body")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidBody, "Invalid request body or missing \"name\" field"))
+		return
+	}
+
+	if err := validate.Struct(body); err != nil || !util.IsValidNamespace(body.Name) {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeValidationFailed, "Invalid namespace name").WithErrors(errs.FieldError{Field: "name", Detail: "must be 1-63 lowercase letters, digits, '-' or '_'"}))
+		return
+	}
+
+	namespace, err := s.namespaces.CreateNamespace(r.Context(), body.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrAlreadyExists):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeNamespaceExists, "Namespace already exists in the system"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error creating namespace")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	util.WriteJson(w, http.StatusCreated, namespaceView(r, namespace))
+}
+
+func (s *StringAnalyzerHandler) DeleteNamespace(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "DeleteNamespace")
+	defer span.End()
+
+	name := chi.URLParam(r, "name")
+
+	if err := s.namespaces.DeleteNamespace(r.Context(), name); err != nil {
+		switch {
+		case errors.Is(err, errs.ErrNamespaceNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeNamespaceNotFound, "Namespace does not exist in the system"))
+
+		case errors.Is(err, errs.ErrDefaultNamespace):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeDefaultNamespace, "The default namespace cannot be deleted"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error deleting namespace")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
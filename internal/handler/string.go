@@ -4,189 +4,301 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-playground/validator/v10"
-	"github.com/justinndidit/stringAnalyzer/internal/database"
+	"github.com/justinndidit/stringAnalyzer/internal/cache"
+	"github.com/justinndidit/stringAnalyzer/internal/config"
 	"github.com/justinndidit/stringAnalyzer/internal/dto"
 	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/metrics"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/render"
 	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/justinndidit/stringAnalyzer/internal/tracing"
 
 	"github.com/justinndidit/stringAnalyzer/internal/util"
 	"github.com/rs/zerolog"
 )
 
 type StringAnalyzerHandler struct {
-	logger *zerolog.Logger
-	db     *database.Database
-	repo   *repository.StringRepository
+	logger     *zerolog.Logger
+	repo       repository.StringStore
+	namespaces repository.NamespaceStore
+	audit      repository.AuditStore
+	keys       repository.APIKeyStore
+	cache      cache.Cache
+	cfg        *config.Config
 }
 
-func NewStringAnalyzerHandler(logger *zerolog.Logger, db *database.Database, repo *repository.StringRepository) *StringAnalyzerHandler {
+// NewStringAnalyzerHandler builds the handler. c is the cache in front of the
+// stores, or nil when caching is disabled.
+func NewStringAnalyzerHandler(logger *zerolog.Logger, repo repository.StringStore, namespaces repository.NamespaceStore, audit repository.AuditStore, keys repository.APIKeyStore, c cache.Cache, cfg *config.Config) *StringAnalyzerHandler {
 	return &StringAnalyzerHandler{
-		logger: logger,
-		db:     db,
-		repo:   repo,
+		logger:     logger,
+		repo:       repo,
+		namespaces: namespaces,
+		audit:      audit,
+		keys:       keys,
+		cache:      c,
+		cfg:        cfg,
 	}
 }
 
+// log returns the request-scoped logger, which carries the request ID.
+func (s *StringAnalyzerHandler) log(r *http.Request) *zerolog.Logger {
+	return requestctx.Logger(r.Context(), s.logger)
+}
+
 func (s *StringAnalyzerHandler) UploadString(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "UploadString")
+	defer span.End()
+
 	var body dto.UploadString
 	defer r.Body.Close()
 
-	if r.ContentLength == 0 {
-		s.logger.Error().Msg("request body is empty!")
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		if writeBodyTooLarge(w, r, err) {
+			return
+		}
+		s.log(r).Error().Err(err).Msg("error reading request body")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidBody, "Invalid request body or missing \"value\" field"))
+		return
+	}
+
+	if len(raw) == 0 {
+		s.log(r).Error().Msg("request body is empty!")
 
-		rb := &util.Envelope{"message": "Invalid request body or missing \"value\" field"}
-		util.WriteJson(w, http.StatusBadRequest, *rb)
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidBody, "Invalid request body or missing \"value\" field"))
 		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		s.logger.Error().Msg(fmt.Sprintf("Error decoding request body: %v", err))
+	// The JSON decoder silently replaces invalid UTF-8 with U+FFFD, so
+	// the raw body is checked first.
+	if s.cfg.Value.InvalidUTF8 == config.PolicyReject && !utf8.Valid(raw) {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidContent, "Request body is not valid UTF-8"))
+		return
+	}
+
+	if err := json.Unmarshal(raw, &body); err != nil {
+		s.log(r).Error().Msg(fmt.Sprintf("Error decoding request body: %v", err))
 
 		var typeErr *errs.InvalidTypeError
 		switch {
 		case errors.As(err, &typeErr):
-			rb := &util.Envelope{"message": "Invalid data type for \"value\" (must be string)"}
-			util.WriteJson(w, http.StatusBadRequest, *rb)
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidType, fmt.Sprintf("Invalid data type for \"%s\" (must be %s)", typeErr.Field, typeErr.Expected)).
+				WithErrors(errs.FieldError{Field: typeErr.Field, Detail: "must be " + typeErr.Expected}))
 
 		default:
-			rb := &util.Envelope{"meesage": "Invalid request body or missing \"value\" field"}
-			util.WriteJson(w, http.StatusBadRequest, *rb)
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidBody, "Invalid request body or missing \"value\" field"))
+		}
+		return
+	}
+
+	if err := util.CheckValue(body.Value, &s.cfg.Value); err != nil {
+		code := errs.CodeInvalidContent
+		if errors.Is(err, errs.ErrValueTooLarge) {
+			code = errs.CodeValueTooLarge
 		}
+		util.WriteProblem(w, r, errs.NewProblem(code, fmt.Sprintf("Invalid \"value\": %v", err)).
+			WithErrors(errs.FieldError{Field: "value", Detail: err.Error()}))
 		return
 	}
 
-	record, err := s.repo.GetStringByValue(r.Context(), body.Value)
+	expiresAt, err := resolveExpiry(body)
+	if err != nil {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeValidationFailed, fmt.Sprintf("Invalid expiry: %v", err)))
+		return
+	}
+
+	record, err := s.repo.GetStringByValue(r.Context(), namespaceFrom(r.Context()), body.Value)
 
 	if err != nil {
-		s.logger.Error().Msg(fmt.Sprintf("error getting string from database: %v", err))
-		rb := &util.Envelope{"message": "Something went wrong"}
-		util.WriteJson(w, http.StatusInternalServerError, *rb)
+		s.log(r).Error().Msg(fmt.Sprintf("error getting string from database: %v", err))
+		util.WriteProblem(w, r, errs.Internal())
+		return
 	}
 
 	if record != nil {
-		s.logger.Info().Msg("string already exists!")
-		rb := &util.Envelope{"message": "String already exists in the system"}
-
-		util.WriteJson(w, http.StatusConflict, *rb)
+		s.log(r).Info().Msg("string already exists!")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeStringExists, "String already exists in the system"))
 		return
 	}
 
 	payload := &dto.CreateString{
 		StringValue:      body.Value,
-		IsPalindrome:     util.IsPalindrome(body.Value),
-		UniqueCharacters: util.CountUniqueCharacters(body.Value),
-		WordCount:        util.CountWords(body.Value),
-		Hash:             util.Hash(body.Value),
-		Length:           util.CharacterCount(body.Value),
+		IsPalindrome:     analyze(r.Context(), "is_palindrome", body.Value, util.IsPalindrome),
+		UniqueCharacters: analyze(r.Context(), "unique_characters", body.Value, util.CountUniqueCharacters),
+		WordCount:        analyze(r.Context(), "word_count", body.Value, util.CountWords),
+		Hash:             analyze(r.Context(), "sha256_hash", body.Value, util.Hash),
+		Length:           analyze(r.Context(), "length", body.Value, util.CharacterCount),
+		Metadata:         body.Metadata,
+		ExpiresAt:        expiresAt,
 	}
 
-	newString, err := s.repo.CreateString(r.Context(), payload)
+	newString, err := s.repo.CreateString(r.Context(), namespaceFrom(r.Context()), payload)
 
 	if err != nil {
-		s.logger.Error().Err(err).Msg("error creating new string")
-		rb := &util.Envelope{"message": "Something went wrong"}
-		util.WriteJson(w, http.StatusInternalServerError, *rb)
-	}
-
-	properties := map[string]any{
-		"length":                  newString.Length,
-		"is_palindrome":           newString.IsPalindrome,
-		"unique_characters":       newString.UniqueCharacters,
-		"word_count":              newString.WordCount,
-		"sha256_hash":             newString.Hash,
-		"character_frequency_map": util.CharacterFrequencyMap(body.Value),
-	}
-	rb := &util.Envelope{
-		"id":         newString.Hash,
-		"value":      newString.StringValue,
-		"properties": properties,
-		"created_at": newString.CreatedAt,
-	}
-	util.WriteJson(w, http.StatusCreated, *rb)
+		switch {
+		case errors.Is(err, errs.ErrAlreadyExists):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeStringExists, "String already exists in the system"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error creating new string")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	w.Header().Set("ETag", stringETag(r, newString, allFields))
+	util.WriteJson(w, http.StatusCreated, stringView(r, newString, allFields))
+}
+
+// resolveExpiry turns the optional ttl or expires_at of an upload into an
+// absolute expiry time.
+func resolveExpiry(body dto.UploadString) (*time.Time, error) {
+	switch {
+	case body.TTL != nil && body.ExpiresAt != nil:
+		return nil, errors.New("provide either \"ttl\" or \"expires_at\", not both")
+
+	case body.TTL != nil:
+		if *body.TTL <= 0 {
+			return nil, errors.New("\"ttl\" must be positive")
+		}
+		expiresAt := time.Now().Add(*body.TTL)
+		return &expiresAt, nil
+
+	case body.ExpiresAt != nil:
+		if !body.ExpiresAt.After(time.Now()) {
+			return nil, errors.New("\"expires_at\" must be in the future")
+		}
+		return body.ExpiresAt, nil
+	}
+
+	return nil, nil
 }
 
 func (s *StringAnalyzerHandler) GetString(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "GetString")
+	defer span.End()
+
 	param := chi.URLParam(r, "string_value")
 
-	record, err := s.repo.GetStringByValue(r.Context(), param)
+	fields, fieldErrs := parseFields(r.URL.Query())
+	if len(fieldErrs) > 0 {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Invalid query parameters").WithErrors(fieldErrs...))
+		return
+	}
+
+	record, err := s.repo.GetStringByValue(r.Context(), namespaceFrom(r.Context()), param)
 
 	if err != nil {
-		s.logger.Error().Err(err).Msg("Invalid query param")
-		rb := &util.Envelope{"message": "Something went wrong!"}
-		util.WriteJson(w, http.StatusInternalServerError, *rb)
+		s.log(r).Error().Err(err).Msg("Invalid query param")
+		util.WriteProblem(w, r, errs.Internal())
+		return
 	}
 
 	if record == nil {
-		rb := &util.Envelope{"message": "String does not exist in the system"}
-		util.WriteJson(w, http.StatusNotFound, *rb)
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeStringNotFound, "String does not exist in the system"))
 		return
 	}
 
-	properties := map[string]any{
-		"length":                  record.Length,
-		"is_palindrome":           record.IsPalindrome,
-		"unique_characters":       record.UniqueCharacters,
-		"word_count":              record.WordCount,
-		"sha256_hash":             record.Hash,
-		"character_frequency_map": util.CharacterFrequencyMap(param),
+	if notModified(w, r, stringETag(r, record, fields)) {
+		return
 	}
+	util.WriteJson(w, http.StatusOK, stringView(r, record, fields))
+}
 
-	rb := &util.Envelope{
-		"id":         record.Hash,
-		"value":      record.StringValue,
-		"properties": properties,
-		"created_at": record.CreatedAt,
-	}
+func (s *StringAnalyzerHandler) GetFilteredStrings(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "GetFilteredStrings")
+	defer span.End()
 
-	util.WriteJson(w, http.StatusOK, *rb)
+	format, ok := negotiate(w, r)
+	if !ok {
+		return
+	}
 
-}
+	// The parse span is ended explicitly once the params validate; the
+	// deferred End only matters on the early returns below.
+	_, parseSpan := tracing.Tracer().Start(r.Context(), "parse_query_params")
+	defer parseSpan.End()
 
-func (s *StringAnalyzerHandler) GetFilteredStrings(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
+	strict := s.cfg.Search.ParamValidation == config.ParamValidationStrict
 
-	var (
-		isPalindrome      *bool
-		minLength         *int
-		maxLength         *int
-		wordCount         *int
-		containsCharacter string
-	)
+	// Values that cannot be parsed are collected rather than returned at
+	// once, so that a strict request lists every problem in one response.
+	var paramErrs []errs.FieldError
+	invalid := func(name, detail string) {
+		paramErrs = append(paramErrs, errs.FieldError{Field: name, Detail: detail})
+	}
+	intParam := func(name string) *int {
+		v := query.Get(name)
+		if v == "" {
+			return nil
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			invalid(name, "must be an integer")
+			return nil
+		}
+		return &i
+	}
+
+	var isPalindrome *bool
 
 	// Parse is_palindrome
 	if v := query.Get("is_palindrome"); v != "" {
-		b, _ := strconv.ParseBool(v)
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			invalid("is_palindrome", "must be true or false")
+		}
 		isPalindrome = &b
 	}
 
-	// Parse min_length
-	if v := query.Get("min_length"); v != "" {
-		if i, err := strconv.Atoi(v); err == nil {
-			minLength = &i
-		}
-	}
+	// Parse min_length, max_length and word_count
+	minLength := intParam("min_length")
+	maxLength := intParam("max_length")
+	wordCount := intParam("word_count")
 
-	// Parse max_length
-	if v := query.Get("max_length"); v != "" {
-		if i, err := strconv.Atoi(v); err == nil {
-			maxLength = &i
+	// Parse contains_character
+	containsCharacter := query.Get("contains_character")
+
+	// Parse tag (repeatable; every tag must be present)
+	var tags []string
+	for _, v := range query["tag"] {
+		if tag := util.NormalizeTag(v); tag != "" {
+			tags = append(tags, tag)
 		}
 	}
 
-	// Parse word_count
-	if v := query.Get("word_count"); v != "" {
-		if i, err := strconv.Atoi(v); err == nil {
-			wordCount = &i
+	// Parse metadata.<key>=<value> (containment) and has_metadata=<key> (repeatable)
+	var metadata map[string]any
+	for key, values := range query {
+		name, ok := strings.CutPrefix(key, "metadata.")
+		if !ok || name == "" || len(values) == 0 {
+			continue
+		}
+		if metadata == nil {
+			metadata = map[string]any{}
 		}
+		metadata[name] = util.ParseMetadataValue(values[0])
 	}
+	metadataKeys := query["has_metadata"]
 
-	// Parse contains_character
-	containsCharacter = query.Get("contains_character")
+	// Parse fields (which fields to compute and return)
+	fields, fieldErrs := parseFields(query)
+	paramErrs = append(paramErrs, fieldErrs...)
+
+	paramErrs = append(paramErrs, checkFilterParamNames(query)...)
 
 	params := dto.QueryParams{
 		IsPalindrome:      isPalindrome,
@@ -194,70 +306,171 @@ func (s *StringAnalyzerHandler) GetFilteredStrings(w http.ResponseWriter, r *htt
 		MaxLength:         maxLength,
 		WordCount:         wordCount,
 		ContainsCharacter: containsCharacter,
+		Query:             strings.TrimSpace(query.Get("q")),
+		Tags:              tags,
+		Collection:        query.Get("collection"),
+		Metadata:          metadata,
+		MetadataKeys:      metadataKeys,
 	}
 
 	// ✅ Validate inputs
-	if err := validator.New().Struct(params); err != nil {
-		s.logger.Error().Err(err).Msg("error validating params")
-		rb := &util.Envelope{"message": "Invalid query parameters"}
-		util.WriteJson(w, http.StatusBadRequest, *rb)
+	err := validate.Struct(params)
+	switch {
+	case strict && (err != nil || len(paramErrs) > 0):
+		s.log(r).Error().Err(err).Interface("param_errors", paramErrs).Msg("error validating params")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Invalid query parameters").
+			WithErrors(paramErrs...).
+			WithErrors(fieldErrors(err)...))
+		return
+
+	case err != nil:
+		s.log(r).Error().Err(err).Msg("error validating params")
+		util.WriteProblem(w, r, validationProblem(errs.CodeInvalidQuery, "Invalid query parameters", err))
 		return
+
+	case len(paramErrs) > 0:
+		// Lenient mode: malformed values fall back to the legacy behaviour
+		// of being ignored, but are logged to help find the clients.
+		s.log(r).Warn().Interface("param_errors", paramErrs).Msg("ignoring invalid query parameters")
 	}
+	parseSpan.End()
 
-	// 🔍 Fetch filtered records
-	records, err := s.repo.GetFilteredStrings(r.Context(), params)
-	if err != nil {
-		s.logger.Error().Err(err).Msg("error fetching records")
-		rb := &util.Envelope{"message": "Something went wrong!"}
-		util.WriteJson(w, http.StatusInternalServerError, *rb)
+	columns := stringColumns(r)
+	if params.Query != "" {
+		columns = append(columns, "rank", "snippet")
+	}
+
+	// 🔍 Fetch filtered records, writing each row as it is read so that
+	// large results are never held in memory.
+	name := fieldNamer(r)
+	stream := &listStream{w: w, r: r, format: format, query: "filter", header: render.ListHeader{
+		Members: map[string]any{name("filters_applied"): filtersView(r, params)},
+		Columns: fields.columns(columns),
+	}}
+	err = s.repo.GetFilteredStrings(r.Context(), namespaceFrom(r.Context()), params, fields.selection(params.Query != ""), stream.start,
+		func(record *model.StringMatch) error {
+			row := stringView(r, &record.String, fields)
+			if params.Query != "" && fields.has("rank") {
+				row["rank"] = record.Rank
+			}
+			if params.Query != "" && fields.has("snippet") {
+				row["snippet"] = record.Snippet
+			}
+			return stream.list.Row(row)
+		})
+	stream.finish(s.log(r), err)
+}
+
+func (s *StringAnalyzerHandler) PatchString(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "PatchString")
+	defer span.End()
+
+	id := chi.URLParam(r, "id")
+	defer r.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeUnsupportedMediaType, "Content-Type must be application/merge-patch+json"))
+		return
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		if writeBodyTooLarge(w, r, err) {
+			return
+		}
+		s.log(r).Error().Err(err).Msg("error decoding merge patch")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidBody, "Request body must be a JSON Merge Patch object"))
 		return
 	}
 
-	// Format and return
-	data := []map[string]any{}
-	for _, record := range records {
-		props := map[string]any{
-			"length":                  record.Length,
-			"is_palindrome":           record.IsPalindrome,
-			"unique_characters":       record.UniqueCharacters,
-			"word_count":              record.WordCount,
-			"sha256_hash":             record.Hash,
-			"character_frequency_map": util.CharacterFrequencyMap(record.StringValue),
+	// An empty merge patch changes nothing, so the string is returned as it
+	// is, provided it matches If-Match.
+	if len(patch) == 0 {
+		record, err := s.repo.GetStringByHash(r.Context(), namespaceFrom(r.Context()), id)
+		if err == nil && record == nil {
+			err = errs.ErrNotFound
+		}
+		if precondition := ifMatch(r); err == nil && precondition != nil {
+			err = precondition(record)
 		}
+		s.writePatchedString(w, r, record, err)
+		return
+	}
 
-		data = append(data, map[string]any{
-			"id":         record.Hash,
-			"value":      record.StringValue,
-			"properties": props,
-			"created_at": record.CreatedAt,
-		})
+	rawMetadata, ok := patch["metadata"]
+	if !ok || len(patch) != 1 {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeImmutableField, "Only \"metadata\" can be modified; every other property is derived from the value"))
+		return
 	}
 
-	respBody := map[string]any{
-		"count":           len(data),
-		"data":            data,
-		"filters_applied": params,
+	var body dto.PatchString
+	if err := json.Unmarshal(rawMetadata, &body.Metadata); err != nil {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidBody, "Invalid \"metadata\" value"))
+		return
+	}
+
+	if _, isObject := body.Metadata.(map[string]any); body.Metadata != nil && !isObject {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidType, "Invalid data type for \"metadata\" (must be object or null)").WithErrors(errs.FieldError{Field: "metadata", Detail: "must be an object or null"}))
+		return
+	}
+
+	record, err := s.repo.PatchMetadata(r.Context(), namespaceFrom(r.Context()), id, body.Metadata, ifMatch(r))
+	s.writePatchedString(w, r, record, err)
+}
+
+// writePatchedString answers a PATCH with the string as it now is, or with
+// the problem err describes.
+func (s *StringAnalyzerHandler) writePatchedString(w http.ResponseWriter, r *http.Request, record *model.String, err error) {
+	if err != nil {
+		switch {
+		case failedIfMatch(r, err):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodePreconditionFailed, "The string does not match If-Match"))
+
+		case errors.Is(err, errs.ErrNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeStringNotFound, "String does not exist in the system"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error patching string")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
 	}
 
-	util.WriteJson(w, http.StatusOK, respBody)
+	w.Header().Set("ETag", stringETag(r, record, allFields))
+	util.WriteJson(w, http.StatusOK, stringView(r, record, allFields))
 }
 
 func (s *StringAnalyzerHandler) DeleteString(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "DeleteString")
+	defer span.End()
+
 	param := chi.URLParam(r, "string_value")
 
-	err := s.repo.DeleteString(r.Context(), param)
+	// ?hard=true skips the trash and removes the string permanently
+	hard := false
+	if v := r.URL.Query().Get("hard"); v != "" {
+		var err error
+		if hard, err = strconv.ParseBool(v); err != nil {
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Invalid query parameters").WithErrors(errs.FieldError{Field: "hard", Detail: "must be true or false"}))
+			return
+		}
+	}
+
+	err := s.repo.DeleteString(r.Context(), namespaceFrom(r.Context()), param, hard, ifMatch(r))
 
 	if err != nil {
 
 		switch {
+		case failedIfMatch(r, err):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodePreconditionFailed, "The string does not match If-Match"))
+
 		case errors.Is(err, errs.ErrNotFound):
-			rb := &util.Envelope{"message": "String does not exist in the system"}
-			util.WriteJson(w, http.StatusNotFound, *rb)
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeStringNotFound, "String does not exist in the system"))
 
 		default:
-			s.logger.Error().Err(err).Msg("error deleting string")
-			rb := &util.Envelope{"message": "Something went wrong!"}
-			util.WriteJson(w, http.StatusInternalServerError, *rb)
+			s.log(r).Error().Err(err).Msg("error deleting string")
+			util.WriteProblem(w, r, errs.Internal())
 		}
 		return
 
@@ -267,47 +480,58 @@ func (s *StringAnalyzerHandler) DeleteString(w http.ResponseWriter, r *http.Requ
 }
 
 func (s *StringAnalyzerHandler) FilterByNaturalLanguage(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "FilterByNaturalLanguage")
+	defer span.End()
+
+	format, ok := negotiate(w, r)
+	if !ok {
+		return
+	}
+
+	fields, fieldErrs := parseFields(r.URL.Query())
+	if len(fieldErrs) > 0 {
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Invalid query parameters").WithErrors(fieldErrs...))
+		return
+	}
+
 	query := r.URL.Query().Get("query")
 	if query == "" {
-		s.logger.Error().Msg("error deleting string")
-		rb := &util.Envelope{"message": "Query string is required"}
-		util.WriteJson(w, http.StatusBadRequest, *rb)
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Query string is required").WithErrors(errs.FieldError{Field: "query", Detail: "is required"}))
+		return
 	}
 
 	// Parse natural language query into filters
+	_, parseSpan := tracing.Tracer().Start(r.Context(), "parse_natural_language")
 	filters, parsedFilters, err := util.ParseNaturalLanguageQuery(query)
+	parseSpan.End()
+	metrics.RecordNaturalLanguageParse(err)
 	if err != nil {
-		s.logger.Error().Msg("unable to parse natural language")
-		rb := &util.Envelope{"message": "Something went wrong"}
-		util.WriteJson(w, http.StatusBadRequest, *rb)
+		s.log(r).Error().Err(err).Msg("unable to parse natural language")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidQuery, "Unable to parse natural language query").
+			WithErrors(errs.FieldError{Field: "query", Detail: err.Error()}))
 		return
 	}
 
 	// Validate filters for conflicts
 	if err = util.ValidateFilters(filters); err != nil {
-		s.logger.Error().Err(err).Msg("conflicted filters")
-		rb := &util.Envelope{"message": "Query parsed but resulted in conflicting filters"}
-		util.WriteJson(w, http.StatusUnprocessableEntity, *rb)
+		s.log(r).Error().Err(err).Msg("conflicted filters")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeConflictingFilters, "Query parsed but resulted in conflicting filters"))
 		return
 	}
 
-	results, err := s.repo.GetFilteredStringsByNaturalLanguage(r.Context(), filters)
-
-	if err != nil {
-		s.logger.Error().Err(err).Msg("Failed to query natural language")
-		rb := &util.Envelope{"message": "Something went wrong"}
-		util.WriteJson(w, http.StatusInternalServerError, *rb)
-		return
-	}
-
-	response := util.Envelope{
-		"data":  results,
-		"count": len(results),
-		"interpreted_query": map[string]any{
-			"original":       query,
-			"parsed_filters": parsedFilters,
+	name := fieldNamer(r)
+	stream := &listStream{w: w, r: r, format: format, query: "natural_language", header: render.ListHeader{
+		Members: map[string]any{
+			name("interpreted_query"): map[string]any{
+				"original":             query,
+				name("parsed_filters"): parsedFiltersView(r, parsedFilters),
+			},
 		},
-	}
-
-	util.WriteJson(w, http.StatusOK, response)
+		Columns: fields.columns(storedStringColumns(r)),
+	}}
+	err = s.repo.GetFilteredStringsByNaturalLanguage(r.Context(), namespaceFrom(r.Context()), filters, fields.selection(false), stream.start,
+		func(record *model.String) error {
+			return stream.list.Row(storedStringView(r, record, fields))
+		})
+	stream.finish(s.log(r), err)
 }
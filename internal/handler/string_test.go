@@ -0,0 +1,373 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+)
+
+// newTestServer serves the string routes of a handler backed by a fresh
+// MemoryStore at the paths routes mounts them under /v1, without
+// authentication or rate limits.
+func newTestServer(t *testing.T, paramValidation string) *httptest.Server {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	store := repository.NewMemoryStore(&logger)
+	cfg := &config.Config{
+		Search: config.SearchConfig{ParamValidation: paramValidation},
+		Value: config.ValueConfig{
+			MaxBytes:          64 << 10,
+			MaxRunes:          16 << 10,
+			InvalidUTF8:       config.PolicyReject,
+			NULBytes:          config.PolicyReject,
+			ControlCharacters: config.PolicyReject,
+		},
+	}
+	h := NewStringAnalyzerHandler(&logger, store, store, store, store, nil, cfg)
+
+	r := chi.NewRouter()
+	r.Use(UseVersion(V1), h.ResolveNamespace)
+	r.Post("/strings", h.UploadString)
+	r.Get("/strings", h.GetFilteredStrings)
+	r.Get("/strings/{string_value}", h.GetString)
+	r.Get("/strings/trash", h.ListTrash)
+	r.Post("/strings/{id}/restore", h.RestoreString)
+	r.Patch("/strings/{id}", h.PatchString)
+	r.Delete("/strings/{string_value}", h.DeleteString)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do sends a request with body, if any, and the given headers as name,
+// value pairs, and decodes the JSON response into a map when there is one.
+func do(t *testing.T, server *httptest.Server, method, path, body string, headers ...string) (*http.Response, map[string]any) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]any
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified {
+		if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+			t.Fatalf("%s %s: decoding the %d response: %v", method, path, resp.StatusCode, err)
+		}
+	}
+	return resp, decoded
+}
+
+func expectStatus(t *testing.T, resp *http.Response, body map[string]any, want int) {
+	t.Helper()
+	if resp.StatusCode != want {
+		t.Fatalf("%s %s: status %d, want %d; body %v", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, want, body)
+	}
+}
+
+func create(t *testing.T, server *httptest.Server, value string) map[string]any {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"value": value})
+	resp, created := do(t, server, http.MethodPost, "/strings", string(body))
+	expectStatus(t, resp, created, http.StatusCreated)
+	return created
+}
+
+// values lists the value of every string in a list response.
+func values(body map[string]any) []string {
+	var list []string
+	for _, item := range body["data"].([]any) {
+		list = append(list, item.(map[string]any)["value"].(string))
+	}
+	return list
+}
+
+func TestCreateGetAndDeleteString(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationStrict)
+
+	created := create(t, server, "A man a plan")
+	if created["id"] != util.Hash("A man a plan") {
+		t.Errorf("id = %v, want the SHA-256 of the value", created["id"])
+	}
+	properties := created["properties"].(map[string]any)
+	if properties["length"] != float64(12) || properties["word_count"] != float64(4) {
+		t.Errorf("properties = %v", properties)
+	}
+
+	resp, body := do(t, server, http.MethodPost, "/strings", `{"value":"A man a plan"}`)
+	expectStatus(t, resp, body, http.StatusConflict)
+	if body["code"] != "string_already_exists" {
+		t.Errorf("code = %v, want string_already_exists", body["code"])
+	}
+
+	path := "/strings/" + url.PathEscape("A man a plan")
+	resp, body = do(t, server, http.MethodGet, path, "")
+	expectStatus(t, resp, body, http.StatusOK)
+	if body["value"] != "A man a plan" {
+		t.Errorf("value = %v", body["value"])
+	}
+
+	resp, body = do(t, server, http.MethodDelete, path, "")
+	expectStatus(t, resp, body, http.StatusNoContent)
+
+	resp, body = do(t, server, http.MethodGet, path, "")
+	expectStatus(t, resp, body, http.StatusNotFound)
+	if body["code"] != "string_not_found" {
+		t.Errorf("code = %v, want string_not_found", body["code"])
+	}
+}
+
+func TestDeleteStringHard(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationStrict)
+	create(t, server, "racecar")
+
+	resp, body := do(t, server, http.MethodDelete, "/strings/racecar?hard=yes", "")
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	if body["code"] != "invalid_query" {
+		t.Errorf("code = %v, want invalid_query", body["code"])
+	}
+	if errors, _ := body["errors"].([]any); len(errors) != 1 || errors[0].(map[string]any)["field"] != "hard" {
+		t.Errorf("errors = %v, want one for hard", body["errors"])
+	}
+
+	resp, body = do(t, server, http.MethodDelete, "/strings/racecar?hard=true", "")
+	expectStatus(t, resp, body, http.StatusNoContent)
+
+	// A hard delete skips the trash.
+	resp, body = do(t, server, http.MethodGet, "/strings/trash", "")
+	expectStatus(t, resp, body, http.StatusOK)
+	if got := values(body); len(got) != 0 {
+		t.Errorf("trash = %v, want it empty", got)
+	}
+}
+
+func TestCreateStringRejectsInvalidBodies(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationStrict)
+
+	for _, tc := range []struct {
+		body   string
+		status int
+		code   string
+	}{
+		{``, http.StatusBadRequest, "invalid_body"},
+		{`{"value":`, http.StatusBadRequest, "invalid_body"},
+		{`{"value":42}`, http.StatusUnprocessableEntity, "invalid_type"},
+		{`{"value":"a\u0000b"}`, http.StatusUnprocessableEntity, "invalid_content"},
+	} {
+		resp, body := do(t, server, http.MethodPost, "/strings", tc.body)
+		if resp.StatusCode != tc.status || body["code"] != tc.code {
+			t.Errorf("POST %q: %d %v, want %d %s", tc.body, resp.StatusCode, body["code"], tc.status, tc.code)
+		}
+	}
+}
+
+func TestFilterStrings(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationStrict)
+	for _, value := range []string{"racecar", "hello world", "level", "brave new world"} {
+		create(t, server, value)
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"is_palindrome=true", []string{"level", "racecar"}},
+		{"word_count=2", []string{"hello world"}},
+		{"min_length=6&max_length=11", []string{"hello world", "racecar"}},
+		{"contains_character=w", []string{"brave new world", "hello world"}},
+		{"q=world", []string{"brave new world", "hello world"}},
+		{"q=world+-brave", []string{"hello world"}},
+		{"is_palindrome=true&word_count=2", nil},
+	} {
+		resp, body := do(t, server, http.MethodGet, "/strings?"+tc.query, "")
+		expectStatus(t, resp, body, http.StatusOK)
+
+		got := values(body)
+		if body["count"] != float64(len(got)) {
+			t.Errorf("%s: count %v for %d rows", tc.query, body["count"], len(got))
+		}
+		sort.Strings(got)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: got %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestFilterStringsRejectsInvalidParametersWhenStrict(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationStrict)
+
+	resp, body := do(t, server, http.MethodGet, "/strings?min_length=abc&is_palindrome=maybe&colour=red", "")
+	expectStatus(t, resp, body, http.StatusBadRequest)
+	if body["code"] != "invalid_query" {
+		t.Errorf("code = %v, want invalid_query", body["code"])
+	}
+
+	fields := map[string]bool{}
+	for _, e := range body["errors"].([]any) {
+		fields[e.(map[string]any)["field"].(string)] = true
+	}
+	for _, field := range []string{"min_length", "is_palindrome", "colour"} {
+		if !fields[field] {
+			t.Errorf("no error for %s in %v", field, body["errors"])
+		}
+	}
+}
+
+func TestFilterStringsIgnoresInvalidParametersWhenLenient(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationLenient)
+	create(t, server, "racecar")
+
+	resp, body := do(t, server, http.MethodGet, "/strings?min_length=abc&colour=red", "")
+	expectStatus(t, resp, body, http.StatusOK)
+	if got := values(body); len(got) != 1 {
+		t.Errorf("got %v, want every string", got)
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationStrict)
+	create(t, server, "racecar")
+	id := util.Hash("racecar")
+
+	resp, body := do(t, server, http.MethodGet, "/strings/racecar", "")
+	expectStatus(t, resp, body, http.StatusOK)
+	etag := resp.Header.Get("ETag")
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		t.Fatalf("ETag = %q, want a strong ETag", etag)
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/strings/racecar", "", "If-None-Match", etag)
+	expectStatus(t, resp, body, http.StatusNotModified)
+
+	patch := `{"metadata":{"source":"test"}}`
+	resp, body = do(t, server, http.MethodPatch, "/strings/"+id, patch, "If-Match", `"stale"`)
+	expectStatus(t, resp, body, http.StatusPreconditionFailed)
+	if body["code"] != "precondition_failed" {
+		t.Errorf("code = %v, want precondition_failed", body["code"])
+	}
+
+	resp, body = do(t, server, http.MethodPatch, "/strings/"+id, patch, "If-Match", etag)
+	expectStatus(t, resp, body, http.StatusOK)
+	updated := resp.Header.Get("ETag")
+	if updated == etag {
+		t.Errorf("ETag did not change when the metadata did")
+	}
+
+	// The first ETag no longer matches, so neither the patch nor the delete
+	// may go through.
+	resp, body = do(t, server, http.MethodPatch, "/strings/"+id, patch, "If-Match", etag)
+	expectStatus(t, resp, body, http.StatusPreconditionFailed)
+	resp, body = do(t, server, http.MethodDelete, "/strings/racecar", "", "If-Match", etag)
+	expectStatus(t, resp, body, http.StatusPreconditionFailed)
+
+	resp, body = do(t, server, http.MethodDelete, "/strings/racecar", "", "If-Match", updated)
+	expectStatus(t, resp, body, http.StatusNoContent)
+
+	// A string that has gone fails If-Match rather than being not found.
+	resp, body = do(t, server, http.MethodDelete, "/strings/racecar", "", "If-Match", updated)
+	expectStatus(t, resp, body, http.StatusPreconditionFailed)
+}
+
+func TestPatchString(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationStrict)
+	create(t, server, "racecar")
+	path := "/strings/" + util.Hash("racecar")
+
+	resp, body := do(t, server, http.MethodPatch, path, `{"metadata":{"source":"test"}}`)
+	expectStatus(t, resp, body, http.StatusOK)
+	etag := resp.Header.Get("ETag")
+
+	// An empty patch changes nothing and returns the string as it is.
+	resp, body = do(t, server, http.MethodPatch, path, `{}`, "If-Match", etag)
+	expectStatus(t, resp, body, http.StatusOK)
+	if body["value"] != "racecar" || body["metadata"].(map[string]any)["source"] != "test" {
+		t.Errorf("empty patch returned %v", body)
+	}
+	if resp.Header.Get("ETag") != etag {
+		t.Errorf("ETag = %q after an empty patch, want %q", resp.Header.Get("ETag"), etag)
+	}
+
+	resp, body = do(t, server, http.MethodPatch, path, `{}`, "If-Match", `"stale"`)
+	expectStatus(t, resp, body, http.StatusPreconditionFailed)
+	resp, body = do(t, server, http.MethodPatch, "/strings/"+util.Hash("missing"), `{}`)
+	expectStatus(t, resp, body, http.StatusNotFound)
+
+	for _, patch := range []string{`{"value":"level"}`, `{"metadata":{},"length":3}`} {
+		resp, body = do(t, server, http.MethodPatch, path, patch)
+		expectStatus(t, resp, body, http.StatusUnprocessableEntity)
+		if body["code"] != "immutable_field" {
+			t.Errorf("PATCH %s: code = %v, want immutable_field", patch, body["code"])
+		}
+	}
+}
+
+func TestTrashAndRestore(t *testing.T) {
+	server := newTestServer(t, config.ParamValidationStrict)
+	create(t, server, "racecar")
+	create(t, server, "level")
+	id := util.Hash("racecar")
+
+	resp, body := do(t, server, http.MethodDelete, "/strings/racecar", "")
+	expectStatus(t, resp, body, http.StatusNoContent)
+
+	resp, body = do(t, server, http.MethodGet, "/strings", "")
+	expectStatus(t, resp, body, http.StatusOK)
+	if got := values(body); len(got) != 1 || got[0] != "level" {
+		t.Errorf("live strings = %v, want [level]", got)
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/strings/trash", "")
+	expectStatus(t, resp, body, http.StatusOK)
+	if got := values(body); len(got) != 1 || got[0] != "racecar" {
+		t.Errorf("trash = %v, want [racecar]", got)
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/strings/"+id+"/restore", "")
+	expectStatus(t, resp, body, http.StatusOK)
+	if body["value"] != "racecar" {
+		t.Errorf("restored %v", body["value"])
+	}
+
+	resp, body = do(t, server, http.MethodPost, "/strings/"+id+"/restore", "")
+	expectStatus(t, resp, body, http.StatusNotFound)
+	if body["code"] != "not_in_trash" {
+		t.Errorf("code = %v, want not_in_trash", body["code"])
+	}
+
+	resp, body = do(t, server, http.MethodGet, "/strings/trash", "")
+	expectStatus(t, resp, body, http.StatusOK)
+	if body["count"] != float64(0) {
+		t.Errorf("trash count = %v after restoring", body["count"])
+	}
+
+	// A hard delete skips the trash.
+	resp, body = do(t, server, http.MethodDelete, "/strings/racecar?hard=true", "")
+	expectStatus(t, resp, body, http.StatusNoContent)
+	resp, body = do(t, server, http.MethodGet, "/strings/trash", "")
+	expectStatus(t, resp, body, http.StatusOK)
+	if body["count"] != float64(0) {
+		t.Errorf("trash count = %v after a hard delete", body["count"])
+	}
+}
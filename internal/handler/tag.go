@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+func (s *StringAnalyzerHandler) AddTags(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "AddTags")
+	defer span.End()
+
+	param := chi.URLParam(r, "string_value")
+
+	var body dto.AddTags
+	defer r.Body.Close()
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		if writeBodyTooLarge(w, r, err) {
+			return
+		}
+		s.log(r).Error().Err(err).Msg("error decoding add tags body")
+		util.WriteProblem(w, r, errs.NewProblem(errs.CodeInvalidBody, "Invalid request body or missing \"tags\" field"))
+		return
+	}
+
+	for i, tag := range body.Tags {
+		body.Tags[i] = util.NormalizeTag(tag)
+	}
+
+	if err := validate.Struct(body); err != nil {
+		s.log(r).Error().Err(err).Msg("error validating tags")
+		util.WriteProblem(w, r, validationProblem(errs.CodeValidationFailed, "\"tags\" must be a non-empty list of tags up to 64 characters", err))
+		return
+	}
+
+	tags, err := s.repo.AddTags(r.Context(), namespaceFrom(r.Context()), util.Hash(param), body.Tags)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeStringNotFound, "String does not exist in the system"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error adding tags")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	rb := &util.Envelope{
+		"id":   util.Hash(param),
+		"tags": tags,
+	}
+	util.WriteJson(w, http.StatusOK, *rb)
+}
+
+func (s *StringAnalyzerHandler) RemoveTag(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "RemoveTag")
+	defer span.End()
+
+	param := chi.URLParam(r, "string_value")
+	tag := util.NormalizeTag(chi.URLParam(r, "tag"))
+
+	err := s.repo.RemoveTag(r.Context(), namespaceFrom(r.Context()), util.Hash(param), tag)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeTagNotFound, "String does not carry this tag"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error removing tag")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/justinndidit/stringAnalyzer/internal/metrics"
+	"github.com/justinndidit/stringAnalyzer/internal/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan starts a span for the named handler method and returns r carrying
+// it, so that repository and query spans nest beneath it.
+func startSpan(r *http.Request, method string) (*http.Request, trace.Span) {
+	ctx, span := tracing.Tracer().Start(r.Context(), "StringAnalyzerHandler."+method)
+	return r.WithContext(ctx), span
+}
+
+// analyze computes one property of value in its own span and records its
+// duration.
+func analyze[T any](ctx context.Context, property string, value string, fn func(string) T) T {
+	_, span := tracing.Tracer().Start(ctx, "analyze."+property)
+	defer span.End()
+
+	return metrics.Analyze(property, value, fn)
+}
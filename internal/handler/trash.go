@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/metrics"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+func (s *StringAnalyzerHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "ListTrash")
+	defer span.End()
+
+	records, err := s.repo.ListTrash(r.Context(), namespaceFrom(r.Context()))
+	if err != nil {
+		s.log(r).Error().Err(err).Msg("error listing trash")
+		util.WriteProblem(w, r, errs.Internal())
+		return
+	}
+
+	metrics.ObserveRows("trash", len(records))
+
+	data := []map[string]any{}
+	for i := range records {
+		data = append(data, trashedStringView(r, &records[i]))
+	}
+
+	rb := &util.Envelope{
+		"count": len(data),
+		"data":  data,
+	}
+	util.WriteJson(w, http.StatusOK, *rb)
+}
+
+func (s *StringAnalyzerHandler) RestoreString(w http.ResponseWriter, r *http.Request) {
+	r, span := startSpan(r, "RestoreString")
+	defer span.End()
+
+	id := chi.URLParam(r, "id")
+
+	record, err := s.repo.RestoreString(r.Context(), namespaceFrom(r.Context()), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrNotFound):
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeNotInTrash, "String is not in the trash"))
+
+		default:
+			s.log(r).Error().Err(err).Msg("error restoring string")
+			util.WriteProblem(w, r, errs.Internal())
+		}
+		return
+	}
+
+	w.Header().Set("ETag", stringETag(r, record, allFields))
+	util.WriteJson(w, http.StatusOK, stringView(r, record, allFields))
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+)
+
+// validate reports fields by the name clients use: the query parameter from
+// a `query` tag, else the JSON property.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"query", "json"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	return v
+}
+
+// validationProblem describes a failed validate.Struct call, listing each
+// invalid field.
+func validationProblem(code errs.Code, detail string, err error) *errs.Problem {
+	return errs.NewProblem(code, detail).WithErrors(fieldErrors(err)...)
+}
+
+// fieldErrors lists the fields a validate.Struct error complains about. It
+// returns nil for any other error, including nil.
+func fieldErrors(err error) []errs.FieldError {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return nil
+	}
+
+	fieldErrs := make([]errs.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fieldErrs = append(fieldErrs, errs.FieldError{Field: fe.Field(), Detail: describeFieldError(fe)})
+	}
+	return fieldErrs
+}
+
+// filterParams are the query parameters GET /strings understands, besides
+// metadata.<key>, and whether each may be repeated.
+var filterParams = map[string]bool{
+	"is_palindrome":      false,
+	"min_length":         false,
+	"max_length":         false,
+	"word_count":         false,
+	"contains_character": false,
+	"q":                  false,
+	"collection":         false,
+	"tag":                true,
+	"has_metadata":       true,
+	"format":             false,
+	"fields":             true,
+}
+
+// checkFilterParamNames reports unknown query parameters and repeated
+// single-valued ones, in a stable order.
+func checkFilterParamNames(query url.Values) []errs.FieldError {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fieldErrs []errs.FieldError
+	for _, name := range names {
+		repeatable, known := filterParams[name]
+		if key, ok := strings.CutPrefix(name, "metadata."); ok && key != "" {
+			known = true
+		}
+
+		switch {
+		case !known:
+			fieldErrs = append(fieldErrs, errs.FieldError{Field: name, Detail: "is not a recognised parameter"})
+		case !repeatable && len(query[name]) > 1:
+			fieldErrs = append(fieldErrs, errs.FieldError{Field: name, Detail: "must be given at most once"})
+		}
+	}
+	return fieldErrs
+}
+
+func describeFieldError(fe validator.FieldError) string {
+	unit := "characters"
+	switch fe.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = "items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		unit = ""
+	}
+	if fe.Param() == "1" {
+		unit = strings.TrimSuffix(unit, "s")
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "len":
+		return strings.TrimSpace(fmt.Sprintf("must be exactly %s %s", fe.Param(), unit))
+	case "min", "gte":
+		return strings.TrimSpace(fmt.Sprintf("must be at least %s %s", fe.Param(), unit))
+	case "max", "lte":
+		return strings.TrimSpace(fmt.Sprintf("must be at most %s %s", fe.Param(), unit))
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fe.Param())
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "hexadecimal":
+		return "must be hexadecimal"
+	default:
+		return fmt.Sprintf("failed the %q check", fe.Tag())
+	}
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// APIVersion is a major version of the API. Every version is served by the
+// same handlers, which render responses in the shape of the version that
+// routed the request.
+type APIVersion int
+
+const (
+	// V1 names fields in snake_case and returns strings in whatever shape
+	// each endpoint grew up with.
+	V1 APIVersion = 1
+	// V2 names fields in camelCase and returns every string in the same
+	// shape, with properties always present and lists never null.
+	V2 APIVersion = 2
+)
+
+type versionContextKey struct{}
+
+// versionFrom returns the version stored by UseVersion.
+func versionFrom(ctx context.Context) APIVersion {
+	if version, ok := ctx.Value(versionContextKey{}).(APIVersion); ok {
+		return version
+	}
+	return V1
+}
+
+// UseVersion stores the API version in the request context for the
+// handlers.
+func UseVersion(version APIVersion) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), versionContextKey{}, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// fieldNamer returns the function that names response fields for the
+// request's version. Only apply it to names the server chooses, never to
+// keys of client data such as metadata.
+func fieldNamer(r *http.Request) func(string) string {
+	if versionFrom(r.Context()) == V2 {
+		return camelCase
+	}
+	return func(name string) string { return name }
+}
+
+// camelCase turns a snake_case name into camelCase, e.g. "sha256_hash" into
+// "sha256Hash".
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
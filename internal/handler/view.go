@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+// The views below render resources for the API version of the request. v1
+// must keep the fields and values clients already parse; shape changes
+// belong behind a check for V2 or later.
+
+// stringView renders a stored string with the selected fields. Properties
+// that are not selected are not computed.
+func stringView(r *http.Request, record *model.String, fields fieldSet) map[string]any {
+	name := fieldNamer(r)
+	v2 := versionFrom(r.Context()) >= V2
+
+	properties := map[string]any{}
+	if fields.hasProperty("length") {
+		properties[name("length")] = record.Length
+	}
+	if fields.hasProperty("is_palindrome") {
+		properties[name("is_palindrome")] = record.IsPalindrome
+	}
+	if fields.hasProperty("unique_characters") {
+		properties[name("unique_characters")] = record.UniqueCharacters
+	}
+	if fields.hasProperty("word_count") {
+		properties[name("word_count")] = record.WordCount
+	}
+	if fields.hasProperty("sha256_hash") {
+		properties[name("sha256_hash")] = record.Hash
+	}
+	if fields.hasProperty("character_frequency_map") {
+		properties[name("character_frequency_map")] = analyze(r.Context(), "character_frequency_map", record.StringValue, util.CharacterFrequencyMap)
+	}
+
+	// v2 always has properties, empty when fields selects none of them.
+	view := map[string]any{}
+	if len(properties) > 0 || v2 {
+		view["properties"] = properties
+	}
+	if fields.has("id") {
+		view["id"] = record.Hash
+	}
+	if fields.has("value") {
+		view["value"] = record.StringValue
+	}
+	if fields.has("namespace") {
+		view["namespace"] = record.Namespace
+	}
+	if fields.has("tags") {
+		view["tags"] = record.Tags
+		if v2 && record.Tags == nil {
+			view["tags"] = []string{}
+		}
+	}
+	if fields.has("collections") {
+		view["collections"] = record.Collections
+		if v2 && record.Collections == nil {
+			view["collections"] = []string{}
+		}
+	}
+	if fields.has("metadata") {
+		view["metadata"] = record.Metadata
+		if v2 && record.Metadata == nil {
+			view["metadata"] = map[string]any{}
+		}
+	}
+	if fields.has("expires_at") {
+		view[name("expires_at")] = record.ExpiresAt
+	}
+	if fields.has("created_at") {
+		view[name("created_at")] = record.CreatedAt
+	}
+	return view
+}
+
+// stringColumns are the CSV columns of stringView rows.
+func stringColumns(r *http.Request) []string {
+	name := fieldNamer(r)
+	return []string{
+		"id",
+		"value",
+		"namespace",
+		"properties." + name("length"),
+		"properties." + name("is_palindrome"),
+		"properties." + name("unique_characters"),
+		"properties." + name("word_count"),
+		"properties." + name("sha256_hash"),
+		"properties." + name("character_frequency_map"),
+		"tags",
+		"collections",
+		"metadata",
+		name("expires_at"),
+		name("created_at"),
+	}
+}
+
+// storedStringView renders a string returned by the natural language
+// filter. v1 has the fields of model.String, as it used to serialise the
+// model itself, with its properties at the top level.
+func storedStringView(r *http.Request, record *model.String, fields fieldSet) map[string]any {
+	if versionFrom(r.Context()) >= V2 {
+		return stringView(r, record, fields)
+	}
+
+	view := map[string]any{
+		"namespace":         record.Namespace,
+		"createdAt":         record.CreatedAt,
+		"value":             record.StringValue,
+		"is_palindrome":     record.IsPalindrome,
+		"unique_characters": record.UniqueCharacters,
+		"word_count":        record.WordCount,
+		"sha256_hash":       record.Hash,
+		"length":            record.Length,
+		"tags":              record.Tags,
+		"collections":       record.Collections,
+		"metadata":          record.Metadata,
+	}
+	if record.DeletedAt != nil {
+		view["deleted_at"] = record.DeletedAt
+	}
+	if record.ExpiresAt != nil {
+		view["expires_at"] = record.ExpiresAt
+	}
+
+	if fields != nil {
+		for key := range view {
+			if !fields.has(key) && !fields.hasProperty(key) {
+				delete(view, key)
+			}
+		}
+	}
+	return view
+}
+
+// storedStringColumns are the CSV columns of storedStringView rows.
+func storedStringColumns(r *http.Request) []string {
+	if versionFrom(r.Context()) >= V2 {
+		return stringColumns(r)
+	}
+	return []string{"sha256_hash", "value", "namespace", "length", "is_palindrome", "unique_characters", "word_count", "tags", "collections", "metadata", "expires_at", "createdAt"}
+}
+
+// trashedStringView renders a string in the trash. v1 lists only its
+// identity and timestamps.
+func trashedStringView(r *http.Request, record *model.String) map[string]any {
+	if versionFrom(r.Context()) >= V2 {
+		view := stringView(r, record, allFields)
+		view["deletedAt"] = record.DeletedAt
+		return view
+	}
+
+	return map[string]any{
+		"id":         record.Hash,
+		"value":      record.StringValue,
+		"created_at": record.CreatedAt,
+		"deleted_at": record.DeletedAt,
+	}
+}
+
+// filtersView renders the filters GET /strings applied. v1 echoes the
+// parameters with their Go field names; later versions list the filters that
+// were set, named after their query parameters.
+func filtersView(r *http.Request, params dto.QueryParams) any {
+	if versionFrom(r.Context()) < V2 {
+		return params
+	}
+
+	view := map[string]any{}
+	if params.IsPalindrome != nil {
+		view["isPalindrome"] = *params.IsPalindrome
+	}
+	if params.MinLength != nil {
+		view["minLength"] = *params.MinLength
+	}
+	if params.MaxLength != nil {
+		view["maxLength"] = *params.MaxLength
+	}
+	if params.WordCount != nil {
+		view["wordCount"] = *params.WordCount
+	}
+	if params.ContainsCharacter != "" {
+		view["containsCharacter"] = params.ContainsCharacter
+	}
+	if params.Query != "" {
+		view["q"] = params.Query
+	}
+	if len(params.Tags) > 0 {
+		view["tags"] = params.Tags
+	}
+	if params.Collection != "" {
+		view["collection"] = params.Collection
+	}
+	if len(params.Metadata) > 0 {
+		view["metadata"] = params.Metadata
+	}
+	if len(params.MetadataKeys) > 0 {
+		view["hasMetadata"] = params.MetadataKeys
+	}
+	return view
+}
+
+// parsedFiltersView renders the filters a natural language query was
+// interpreted as.
+func parsedFiltersView(r *http.Request, parsed map[string]any) map[string]any {
+	name := fieldNamer(r)
+
+	view := make(map[string]any, len(parsed))
+	for key, value := range parsed {
+		view[name(key)] = value
+	}
+	return view
+}
+
+// cacheStatsColumns are the CSV columns of the cache statistics.
+func cacheStatsColumns(r *http.Request) []string {
+	name := fieldNamer(r)
+	return []string{"enabled", "hits", "misses", name("hit_ratio"), "evictions", "entries", "capacity"}
+}
+
+func collectionView(r *http.Request, collection *model.Collection) map[string]any {
+	name := fieldNamer(r)
+	return map[string]any{
+		"name":               collection.Name,
+		name("created_at"):   collection.CreatedAt,
+		name("string_count"): collection.StringCount,
+	}
+}
+
+func namespaceView(r *http.Request, namespace *model.Namespace) map[string]any {
+	name := fieldNamer(r)
+	return map[string]any{
+		"name":               namespace.Name,
+		name("created_at"):   namespace.CreatedAt,
+		name("string_count"): namespace.StringCount,
+	}
+}
+
+// apiKeyView renders an API key's description; the key itself is never
+// part of it.
+func apiKeyView(r *http.Request, key *model.APIKey) map[string]any {
+	name := fieldNamer(r)
+	view := map[string]any{
+		"id":               key.ID,
+		"name":             key.Name,
+		"prefix":           key.Prefix,
+		"scopes":           key.Scopes,
+		name("created_at"): key.CreatedAt,
+	}
+	if key.RevokedAt != nil {
+		view[name("revoked_at")] = key.RevokedAt
+	}
+	return view
+}
+
+// auditEventView renders an audit event. Before and After are snapshots of
+// the stored string and keep the shape they were recorded in.
+func auditEventView(r *http.Request, event *model.AuditEvent) map[string]any {
+	name := fieldNamer(r)
+	return map[string]any{
+		"id":                event.ID,
+		name("occurred_at"): event.OccurredAt,
+		"namespace":         event.Namespace,
+		"actor":             event.Actor,
+		name("request_id"):  event.RequestID,
+		"operation":         event.Operation,
+		name("string_id"):   event.StringID,
+		"before":            event.Before,
+		"after":             event.After,
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/justinndidit/stringAnalyzer/internal/cache"
+	"github.com/justinndidit/stringAnalyzer/internal/database"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// RegisterDatabase exposes connection pool statistics for db. It is a no-op
+// for the memory driver, where db is nil.
+func RegisterDatabase(db *database.Database) {
+	switch {
+	case db == nil:
+	case db.Pool != nil:
+		Registry.MustRegister(newPoolCollector(db.Pool))
+	case db.SQL != nil:
+		Registry.MustRegister(collectors.NewDBStatsCollector(db.SQL, "sqlite"))
+	}
+}
+
+// RegisterCache exposes the counters of c. It is a no-op when c is nil.
+func RegisterCache(c cache.Cache) {
+	if c != nil {
+		Registry.MustRegister(&cacheCollector{cache: c})
+	}
+}
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+}
+
+// poolCollector reads pgxpool statistics on every scrape.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired, idle, total, max            *prometheus.Desc
+	acquires, emptyAcquires, canceled     *prometheus.Desc
+	acquireDuration, emptyAcquireWaitTime *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	return &poolCollector{
+		pool:                 pool,
+		acquired:             poolDesc("acquired_connections", "Connections currently checked out of the pool."),
+		idle:                 poolDesc("idle_connections", "Idle connections in the pool."),
+		total:                poolDesc("total_connections", "Connections in the pool, including ones being established."),
+		max:                  poolDesc("max_connections", "Maximum size of the pool."),
+		acquires:             poolDesc("acquires_total", "Successful connection acquires."),
+		emptyAcquires:        poolDesc("empty_acquires_total", "Acquires that had to wait because the pool had no idle connection."),
+		canceled:             poolDesc("canceled_acquires_total", "Acquires canceled by their context."),
+		acquireDuration:      poolDesc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+		emptyAcquireWaitTime: poolDesc("acquire_wait_seconds_total", "Total time acquires spent waiting for a connection to free up."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		c.acquired, c.idle, c.total, c.max,
+		c.acquires, c.emptyAcquires, c.canceled,
+		c.acquireDuration, c.emptyAcquireWaitTime,
+	} {
+		ch <- desc
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceled, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireWaitTime, prometheus.CounterValue, stat.EmptyAcquireWaitTime().Seconds())
+}
+
+func cacheDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "cache", name), help, nil, nil)
+}
+
+var (
+	cacheHitsDesc      = cacheDesc("hits_total", "Cache lookups that found an entry.")
+	cacheMissesDesc    = cacheDesc("misses_total", "Cache lookups that found no entry.")
+	cacheEvictionsDesc = cacheDesc("evictions_total", "Entries evicted to make room for new ones.")
+	cacheEntriesDesc   = cacheDesc("entries", "Entries currently cached.")
+)
+
+// cacheCollector reads cache.Stats on every scrape.
+type cacheCollector struct {
+	cache cache.Cache
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	ch <- cacheEvictionsDesc
+	ch <- cacheEntriesDesc
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.cache.Stats()
+
+	ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(cacheEvictionsDesc, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(cacheEntriesDesc, prometheus.GaugeValue, float64(stats.Entries))
+}
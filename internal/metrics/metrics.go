@@ -0,0 +1,133 @@
+// Package metrics defines the Prometheus metrics the service exposes on
+// /metrics and the helpers that record them.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "stringanalyzer"
+
+// Registry holds every metric served by Handler.
+var Registry = prometheus.NewRegistry()
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled, by method, chi route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency, by method and chi route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	analysisDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "analysis_duration_seconds",
+		Help:      "Time spent computing each string property.",
+		Buckets:   prometheus.ExponentialBuckets(1e-6, 4, 10),
+	}, []string{"property"})
+
+	naturalLanguageParses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "natural_language_parses_total",
+		Help:      "Natural language queries parsed, by result (success or failure).",
+	}, []string{"result"})
+
+	queryRows = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "query_rows_returned",
+		Help:      "Rows returned per list query, by query.",
+		Buckets:   []float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000, 5000},
+	}, []string{"query"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected with 429, by route and reason (rate, quota or auth). Auth failure limits apply to every route, labelled \"*\".",
+	}, []string{"route", "reason"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		analysisDuration,
+		naturalLanguageParses,
+		queryRows,
+		rateLimited,
+	)
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Middleware records the count and latency of every request, labelled with
+// the chi route pattern rather than the raw path so that values such as
+// /strings/{string_value} don't explode the label cardinality.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		requestsTotal.WithLabelValues(r.Method, route, strconv.Itoa(status)).Inc()
+		requestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Analyze computes one property of value with fn and records how long it
+// took.
+func Analyze[T any](property string, value string, fn func(string) T) T {
+	start := time.Now()
+	result := fn(value)
+	analysisDuration.WithLabelValues(property).Observe(time.Since(start).Seconds())
+	return result
+}
+
+// RecordNaturalLanguageParse counts a natural language parse as a success
+// when err is nil and a failure otherwise.
+func RecordNaturalLanguageParse(err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	naturalLanguageParses.WithLabelValues(result).Inc()
+}
+
+// ObserveRows records how many rows a list query returned.
+func ObserveRows(query string, rows int) {
+	queryRows.WithLabelValues(query).Observe(float64(rows))
+}
+
+// RecordRateLimited counts a request to route rejected for reason.
+func RecordRateLimited(route, reason string) {
+	rateLimited.WithLabelValues(route, reason).Inc()
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+)
+
+// MaxBodySize rejects requests declaring a body larger than limit bytes with
+// 413 and caps the rest at limit, so that reading past it fails with
+// *http.MaxBytesError.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				util.WriteProblem(w, r, errs.NewProblem(errs.CodeBodyTooLarge, fmt.Sprintf("Request body exceeds the %d byte limit", limit)))
+				return
+			}
+
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+)
+
+// corsPolicy is the CORS configuration prepared for matching.
+type corsPolicy struct {
+	anyOrigin   bool
+	origins     map[string]bool
+	wildcards   [][2]string // prefix and suffix around "*"
+	methods     map[string]bool
+	anyHeader   bool
+	headers     map[string]bool
+	allowMethod string
+	allowHeader string
+	expose      string
+	credentials bool
+	maxAge      string
+}
+
+// CORS answers preflight requests for every route and adds the CORS response
+// headers to requests from allowed origins, as configured in cfg. Requests
+// from other origins are served without CORS headers, which browsers reject.
+func CORS(cfg *config.ServerConfig) func(http.Handler) http.Handler {
+	p := &corsPolicy{
+		origins:     map[string]bool{},
+		methods:     map[string]bool{},
+		headers:     map[string]bool{},
+		allowMethod: strings.Join(cfg.CORSAllowedMethods, ", "),
+		allowHeader: strings.Join(cfg.CORSAllowedHeaders, ", "),
+		expose:      strings.Join(cfg.CORSExposedHeaders, ", "),
+		credentials: cfg.CORSAllowCredentials,
+		maxAge:      strconv.Itoa(cfg.CORSMaxAge),
+	}
+
+	for _, origin := range cfg.CORSAllowedOrigins {
+		origin = strings.ToLower(origin)
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			p.wildcards = append(p.wildcards, [2]string{prefix, suffix})
+		default:
+			p.origins[origin] = true
+		}
+	}
+	for _, method := range cfg.CORSAllowedMethods {
+		p.methods[strings.ToUpper(method)] = true
+	}
+	for _, header := range cfg.CORSAllowedHeaders {
+		if header == "*" {
+			p.anyHeader = true
+		}
+		p.headers[http.CanonicalHeaderKey(header)] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if preflight {
+				p.preflight(w, r, origin)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+			if p.allowsOrigin(origin) {
+				p.setOrigin(w, origin)
+				if p.expose != "" {
+					w.Header().Set("Access-Control-Expose-Headers", p.expose)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// preflight answers an OPTIONS preflight without reaching the router. A
+// disallowed origin, method or header gets a bare 204 so the browser blocks
+// the actual request.
+func (p *corsPolicy) preflight(w http.ResponseWriter, r *http.Request, origin string) {
+	h := w.Header()
+	h.Add("Vary", "Origin")
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	requested := r.Header.Get("Access-Control-Request-Headers")
+
+	if !p.allowsOrigin(origin) || !p.allowsMethod(method) || !p.allowsHeaders(requested) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	p.setOrigin(w, origin)
+	h.Set("Access-Control-Allow-Methods", p.allowMethod)
+	if p.anyHeader && requested != "" {
+		h.Set("Access-Control-Allow-Headers", requested)
+	} else if p.allowHeader != "" {
+		h.Set("Access-Control-Allow-Headers", p.allowHeader)
+	}
+	if p.maxAge != "0" {
+		h.Set("Access-Control-Max-Age", p.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setOrigin writes the allowed origin. Credentialed responses must name the
+// origin rather than "*".
+func (p *corsPolicy) setOrigin(w http.ResponseWriter, origin string) {
+	if p.anyOrigin && !p.credentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+	if p.credentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (p *corsPolicy) allowsOrigin(origin string) bool {
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+	if p.origins[origin] {
+		return true
+	}
+	for _, w := range p.wildcards {
+		if len(origin) > len(w[0])+len(w[1]) && strings.HasPrefix(origin, w[0]) && strings.HasSuffix(origin, w[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsMethod reports whether method may be used cross-origin. Simple
+// methods are always allowed.
+func (p *corsPolicy) allowsMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost:
+		return true
+	}
+	return p.methods[method]
+}
+
+// allowsHeaders reports whether every header in the comma-separated
+// Access-Control-Request-Headers value is allowed.
+func (p *corsPolicy) allowsHeaders(requested string) bool {
+	if p.anyHeader {
+		return true
+	}
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !p.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
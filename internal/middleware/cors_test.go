@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+)
+
+func corsHandler(cfg *config.ServerConfig) http.Handler {
+	return CORS(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestCORSWildcardOrigins(t *testing.T) {
+	h := corsHandler(&config.ServerConfig{
+		CORSAllowedOrigins: []string{"https://*.example.com", "http://localhost:3000"},
+		CORSAllowedMethods: []string{"GET", "PATCH"},
+	})
+
+	for _, tc := range []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://app.example.com", true},
+		{"https://a.b.example.com", true},
+		{"https://APP.Example.com", true},
+		{"http://localhost:3000", true},
+		{"https://example.com", false},
+		{"https://.example.com", false},
+		{"http://app.example.com", false},
+		{"https://app.example.com.evil.io", false},
+		{"https://evilexample.com", false},
+		{"http://localhost:3001", false},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/strings", nil)
+		req.Header.Set("Origin", tc.origin)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		got := rec.Header().Get("Access-Control-Allow-Origin")
+		if tc.allowed && got != tc.origin {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want the origin", tc.origin, got)
+		}
+		if !tc.allowed && got != "" {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want none", tc.origin, got)
+		}
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	for _, tc := range []struct {
+		credentials bool
+		want        string
+	}{
+		{false, "*"},
+		// Credentialed responses cannot use "*".
+		{true, "https://app.example.com"},
+	} {
+		h := corsHandler(&config.ServerConfig{
+			CORSAllowedOrigins:   []string{"*"},
+			CORSAllowCredentials: tc.credentials,
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/strings", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tc.want {
+			t.Errorf("credentials %v: Access-Control-Allow-Origin = %q, want %q", tc.credentials, got, tc.want)
+		}
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	h := corsHandler(&config.ServerConfig{
+		CORSAllowedOrigins: []string{"https://*.example.com"},
+		CORSAllowedMethods: []string{"GET", "PATCH"},
+		CORSAllowedHeaders: []string{"Content-Type", "If-Match"},
+		CORSMaxAge:         600,
+	})
+
+	for _, tc := range []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		allowed bool
+	}{
+		{"allowed", "https://app.example.com", "PATCH", "content-type, if-match", true},
+		{"simple method", "https://app.example.com", "POST", "", true},
+		{"wildcard miss", "https://example.com", "PATCH", "", false},
+		{"method", "https://app.example.com", "DELETE", "", false},
+		{"header", "https://app.example.com", "PATCH", "X-Custom", false},
+	} {
+		req := httptest.NewRequest(http.MethodOptions, "/strings", nil)
+		req.Header.Set("Origin", tc.origin)
+		req.Header.Set("Access-Control-Request-Method", tc.method)
+		if tc.headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", tc.headers)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("%s: status %d, want 204", tc.name, rec.Code)
+		}
+		got := rec.Header().Get("Access-Control-Allow-Origin")
+		if tc.allowed != (got == tc.origin) {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, allowed %v", tc.name, got, tc.allowed)
+		}
+		if tc.allowed && rec.Header().Get("Access-Control-Max-Age") != "600" {
+			t.Errorf("%s: Access-Control-Max-Age = %q", tc.name, rec.Header().Get("Access-Control-Max-Age"))
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Deprecated marks every response as coming from a deprecated path: a
+// Deprecation header with the time it was deprecated (RFC 9745), a Sunset
+// header with the time it will stop working (RFC 8594) and a Link to the
+// same resource under successor, e.g. "/v1".
+func Deprecated(deprecation, sunset time.Time, successor string) func(http.Handler) http.Handler {
+	deprecationHeader := fmt.Sprintf("@%d", deprecation.Unix())
+	sunsetHeader := sunset.UTC().Format(http.TimeFormat)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("Deprecation", deprecationHeader)
+			h.Set("Sunset", sunsetHeader)
+			h.Add("Link", fmt.Sprintf("<%s%s>; rel=\"successor-version\"", successor, r.URL.EscapedPath()))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AccessLog stores a logger tagged with the request ID (and trace ID when the
+// request is traced) in the request context, then writes one access log line
+// per request once it completes. It must run after RequestID.
+func AccessLog(logger *zerolog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			fields := logger.With().Str("request_id", requestctx.RequestID(r.Context()))
+			if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+				fields = fields.Str("trace_id", sc.TraceID().String())
+			}
+			reqLogger := fields.Logger()
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(requestctx.WithLogger(r.Context(), &reqLogger)))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			event := reqLogger.Info()
+			switch {
+			case status >= http.StatusInternalServerError:
+				event = reqLogger.Error()
+			case status >= http.StatusBadRequest:
+				event = reqLogger.Warn()
+			}
+
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				event = event.Str("route", rctx.RoutePattern())
+			}
+
+			event.
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", status).
+				Int("bytes", ww.BytesWritten()).
+				Dur("latency", time.Since(start)).
+				Str("remote_addr", r.RemoteAddr).
+				Str("user_agent", r.UserAgent()).
+				Msg("request completed")
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+)
+
+// Recoverer turns a panic in a handler into a logged error and a JSON 500
+// response. http.ErrAbortHandler is re-raised so the server can abort the
+// connection as intended.
+func Recoverer(logger *zerolog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				requestctx.Logger(r.Context(), logger).Error().
+					Str("panic", fmt.Sprint(rec)).
+					Bytes("stack", debug.Stack()).
+					Msg("recovered from panic")
+
+				util.WriteProblem(w, r, errs.Internal())
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+// Package middleware holds the HTTP middleware mounted in front of every
+// route: request IDs, access logging and panic recovery.
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+// maxRequestIDLength bounds client-supplied request IDs so they cannot bloat
+// logs and audit rows.
+const maxRequestIDLength = 128
+
+// RequestID stores a request ID in the request context and echoes it in the
+// X-Request-ID response header. The client's X-Request-ID is reused when it
+// is present and well-formed; otherwise a new UUID is generated.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestctx.RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(requestctx.RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(requestctx.WithRequestID(r.Context(), id)))
+	})
+}
+
+// isValidRequestID accepts non-empty IDs of printable ASCII without spaces.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
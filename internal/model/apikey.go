@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// APIKey describes an API key. The key itself is only known to its holder;
+// the store keeps a hash of it.
+type APIKey struct {
+	ID        int64      `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Prefix    string     `json:"prefix" db:"prefix"`
+	Scopes    []string   `json:"scopes" db:"scopes"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audit operations recorded against the strings table.
+const (
+	AuditCreate          = "create"
+	AuditUpdate          = "update"
+	AuditDelete          = "delete"
+	AuditRestore         = "restore"
+	AuditPurge           = "purge"
+	AuditExpire          = "expire"
+	AuditDeleteNamespace = "delete_namespace"
+)
+
+type AuditEvent struct {
+	ID         int64           `json:"id" db:"id"`
+	OccurredAt time.Time       `json:"occurred_at" db:"occurred_at"`
+	Namespace  string          `json:"namespace" db:"namespace"`
+	Actor      string          `json:"actor" db:"actor"`
+	RequestID  *string         `json:"request_id" db:"request_id"`
+	Operation  string          `json:"operation" db:"operation"`
+	StringID   *string         `json:"string_id" db:"sha256_hash"`
+	Before     json.RawMessage `json:"before" db:"before"`
+	After      json.RawMessage `json:"after" db:"after"`
+}
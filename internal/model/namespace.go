@@ -0,0 +1,11 @@
+package model
+
+import (
+	"time"
+)
+
+type Namespace struct {
+	Name        string    `json:"name" db:"name"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	StringCount int       `json:"string_count" db:"string_count"`
+}
@@ -5,11 +5,26 @@ import (
 )
 
 type String struct {
-	CreatedAt        time.Time `json:"createdAt" db:"created_at"`
-	StringValue      string    `json:"value" db:"string_value"`
-	IsPalindrome     bool      `json:"is_palindrome" db:"is_palindrome"`
-	UniqueCharacters int       `json:"unique_characters" db:"unique_characters"`
-	WordCount        int       `json:"word_count" db:"word_count"`
-	Hash             string    `json:"sha256_hash" db:"sha256_hash"`
-	Length           int       `json:"length" db:"length"`
+	Namespace        string         `json:"namespace" db:"namespace"`
+	CreatedAt        time.Time      `json:"createdAt" db:"created_at"`
+	StringValue      string         `json:"value" db:"string_value"`
+	IsPalindrome     bool           `json:"is_palindrome" db:"is_palindrome"`
+	UniqueCharacters int            `json:"unique_characters" db:"unique_characters"`
+	WordCount        int            `json:"word_count" db:"word_count"`
+	Hash             string         `json:"sha256_hash" db:"sha256_hash"`
+	Length           int            `json:"length" db:"length"`
+	Tags             []string       `json:"tags" db:"tags"`
+	Collections      []string       `json:"collections" db:"collections"`
+	Metadata         map[string]any `json:"metadata" db:"metadata"`
+	DeletedAt        *time.Time     `json:"deleted_at,omitempty" db:"deleted_at"`
+	ExpiresAt        *time.Time     `json:"expires_at,omitempty" db:"expires_at"`
+}
+
+// StringMatch is a String returned from a filtered query together with its
+// full-text search rank and highlighted snippet. Rank and Snippet are zero
+// when the query carried no search terms.
+type StringMatch struct {
+	String
+	Rank    float32 `json:"rank" db:"rank"`
+	Snippet string  `json:"snippet" db:"snippet"`
 }
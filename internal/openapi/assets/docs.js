@@ -0,0 +1,303 @@
+// Renders the OpenAPI document named by #docs's data-document attribute. It
+// is served with the API rather than loaded from a CDN so that /docs works
+// without internet access.
+(function () {
+  "use strict";
+
+  var root = document.getElementById("docs");
+  var methods = ["get", "post", "put", "patch", "delete"];
+  var doc;
+
+  function el(tag, attrs) {
+    var node = document.createElement(tag);
+    for (var name in attrs || {}) {
+      if (name === "text") {
+        node.textContent = attrs[name];
+      } else {
+        node.setAttribute(name, attrs[name]);
+      }
+    }
+    for (var i = 2; i < arguments.length; i++) {
+      var child = arguments[i];
+      if (child == null) {
+        continue;
+      }
+      node.appendChild(typeof child === "string" ? document.createTextNode(child) : child);
+    }
+    return node;
+  }
+
+  // description renders text as paragraphs, one per blank-line separated
+  // block.
+  function description(text) {
+    var node = el("div", { class: "description" });
+    (text || "").split(/\n\s*\n/).forEach(function (block) {
+      if (block.trim()) {
+        node.appendChild(el("p", { text: block.trim() }));
+      }
+    });
+    return node;
+  }
+
+  function refName(ref) {
+    return ref.slice(ref.lastIndexOf("/") + 1);
+  }
+
+  // resolve follows a local $ref to what it names.
+  function resolve(value) {
+    while (value && value.$ref) {
+      var target = doc;
+      value.$ref.replace(/^#\//, "").split("/").forEach(function (key) {
+        target = target && target[key];
+      });
+      value = target;
+    }
+    return value;
+  }
+
+  // typeOf renders a short description of schema's type, linking named
+  // schemas.
+  function typeOf(schema) {
+    if (!schema) {
+      return el("span", { class: "type", text: "any" });
+    }
+    if (schema.$ref) {
+      return el("a", { class: "type", href: "#schema-" + refName(schema.$ref), text: refName(schema.$ref) });
+    }
+
+    var alternatives = schema.oneOf || schema.anyOf;
+    if (alternatives) {
+      var span = el("span");
+      alternatives.forEach(function (alternative, i) {
+        if (i > 0) {
+          span.appendChild(document.createTextNode(" | "));
+        }
+        span.appendChild(typeOf(alternative));
+      });
+      return span;
+    }
+
+    var type = [].concat(schema.type || "object");
+    var node = el("span", { class: "type" });
+    type.forEach(function (t, i) {
+      if (i > 0) {
+        node.appendChild(document.createTextNode(" | "));
+      }
+      if (t === "array") {
+        node.appendChild(document.createTextNode("array of "));
+        node.appendChild(typeOf(schema.items));
+      } else {
+        node.appendChild(document.createTextNode(t + (schema.format ? " (" + schema.format + ")" : "")));
+      }
+    });
+    if (schema.enum) {
+      node.appendChild(document.createTextNode(": " + schema.enum.map(function (v) { return JSON.stringify(v); }).join(", ")));
+    }
+    return node;
+  }
+
+  // constraints lists the validation keywords of schema worth showing.
+  function constraints(schema) {
+    var parts = [];
+    ["minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems", "pattern", "default"].forEach(function (key) {
+      if (schema && schema[key] !== undefined) {
+        parts.push(key + " " + JSON.stringify(schema[key]));
+      }
+    });
+    return parts.length ? el("span", { class: "muted", text: " (" + parts.join(", ") + ")" }) : null;
+  }
+
+  // properties renders the members of an object schema as a nested list.
+  function properties(schema) {
+    schema = schema || {};
+    var required = schema.required || [];
+    var list = el("ul", { class: "schema" });
+
+    Object.keys(schema.properties || {}).forEach(function (name) {
+      var property = schema.properties[name];
+      var item = el("li", {},
+        el("code", { class: required.indexOf(name) >= 0 ? "required" : "", text: name }),
+        " ", typeOf(property), constraints(property));
+      if (property.description) {
+        item.appendChild(el("div", { class: "muted", text: property.description }));
+      }
+      if (!property.$ref && property.properties) {
+        item.appendChild(properties(property));
+      }
+      list.appendChild(item);
+    });
+
+    if (schema.additionalProperties && schema.additionalProperties !== true) {
+      list.appendChild(el("li", {}, el("code", { text: "<key>" }), " ", typeOf(schema.additionalProperties)));
+    }
+    return list;
+  }
+
+  function content(media) {
+    var list = el("ul", { class: "schema" });
+    Object.keys(media || {}).forEach(function (type) {
+      var schema = media[type].schema;
+      var item = el("li", {}, el("code", { text: type }), " ", typeOf(schema));
+      if (schema && schema.description) {
+        item.appendChild(el("div", { class: "muted", text: schema.description }));
+      }
+      list.appendChild(item);
+    });
+    return list;
+  }
+
+  function parameters(params) {
+    var table = el("table", {}, el("tr", {},
+      el("th", { text: "Name" }), el("th", { text: "In" }), el("th", { text: "Type" }), el("th", { text: "Description" })));
+    params.forEach(function (param) {
+      param = resolve(param);
+      table.appendChild(el("tr", {},
+        el("td", {}, el("code", { class: param.required ? "required" : "", text: param.name })),
+        el("td", { text: param.in }),
+        el("td", {}, typeOf(param.schema), constraints(param.schema)),
+        el("td", { text: param.description || "" })));
+    });
+    return table;
+  }
+
+  function responses(all) {
+    var table = el("table", {}, el("tr", {},
+      el("th", { text: "Status" }), el("th", { text: "Description" }), el("th", { text: "Body" })));
+    Object.keys(all).forEach(function (status) {
+      var response = resolve(all[status]);
+      var headers = Object.keys(response.headers || {});
+      var cell = el("td", { text: response.description || "" });
+      if (headers.length) {
+        cell.appendChild(el("div", { class: "muted", text: "Headers: " + headers.join(", ") }));
+      }
+      table.appendChild(el("tr", {},
+        el("td", {}, el("code", { text: status })),
+        cell,
+        el("td", {}, response.content ? content(response.content) : el("span", { class: "muted", text: "none" }))));
+    });
+    return table;
+  }
+
+  function operation(path, method, op) {
+    var summary = el("summary", {},
+      el("span", { class: "method " + method, text: method }),
+      el("span", { class: "path", text: path }),
+      el("span", { text: op.summary || "" }),
+      op["x-required-scope"] ? el("span", { class: "scope", text: "scope: " + op["x-required-scope"] }) : null);
+
+    var body = el("div", { class: "body" });
+    if (op.description) {
+      body.appendChild(description(op.description));
+    }
+    if (op.parameters && op.parameters.length) {
+      body.appendChild(el("h4", { text: "Parameters" }));
+      body.appendChild(parameters(op.parameters));
+    }
+    if (op.requestBody) {
+      body.appendChild(el("h4", { text: "Request body" + (op.requestBody.required ? "" : " (optional)") }));
+      body.appendChild(content(op.requestBody.content));
+    }
+    body.appendChild(el("h4", { text: "Responses" }));
+    body.appendChild(responses(op.responses || {}));
+
+    return el("details", { class: "operation" + (op.deprecated ? " deprecated" : ""), id: op.operationId || "" }, summary, body);
+  }
+
+  function render() {
+    root.textContent = "";
+    root.appendChild(el("h1", { text: doc.info.title }));
+    root.appendChild(el("p", { class: "muted", text: "Version " + doc.info.version + " · OpenAPI " + doc.openapi + " · " },
+      el("a", { href: root.getAttribute("data-document"), text: "Download the document" })));
+    root.appendChild(description(doc.info.description));
+
+    var groups = {};
+    var order = (doc.tags || []).map(function (tag) { return tag.name; });
+    Object.keys(doc.paths).forEach(function (path) {
+      methods.forEach(function (method) {
+        var op = doc.paths[path][method];
+        if (!op) {
+          return;
+        }
+        var tag = (op.tags && op.tags[0]) || "other";
+        if (order.indexOf(tag) < 0) {
+          order.push(tag);
+        }
+        (groups[tag] = groups[tag] || []).push(operation(path, method, op));
+      });
+    });
+
+    order.forEach(function (tag) {
+      if (!groups[tag]) {
+        return;
+      }
+      root.appendChild(el("h2", { id: "tag-" + tag, text: tag }));
+      groups[tag].forEach(function (node) {
+        root.appendChild(node);
+      });
+    });
+
+    var schemas = (doc.components && doc.components.schemas) || {};
+    root.appendChild(el("h2", { id: "schemas", text: "Schemas" }));
+    Object.keys(schemas).forEach(function (name) {
+      var schema = schemas[name];
+      var section = el("section", { id: "schema-" + name }, el("h3", { text: name }));
+      if (schema.description) {
+        section.appendChild(description(schema.description));
+      }
+      section.appendChild(schema.properties || schema.additionalProperties
+        ? properties(schema)
+        : el("p", {}, typeOf(schema)));
+      root.appendChild(section);
+    });
+
+    if (location.hash) {
+      var target = document.getElementById(location.hash.slice(1));
+      if (target) {
+        target.open = true;
+        target.scrollIntoView();
+      }
+    }
+  }
+
+  // askForKey shows a form for an API key with the read scope, which the
+  // server requires for the document unless AUTH_PUBLIC_DOCS is set.
+  function askForKey(message) {
+    var input = el("input", { type: "password", autocomplete: "off", placeholder: "API key", size: "40" });
+    var form = el("form", {}, el("p", { text: message }), input, " ", el("button", { type: "submit", text: "Load" }));
+    form.addEventListener("submit", function (event) {
+      event.preventDefault();
+      sessionStorage.setItem("docs-api-key", input.value);
+      load();
+    });
+    root.textContent = "";
+    root.appendChild(form);
+    input.focus();
+  }
+
+  function load() {
+    var key = sessionStorage.getItem("docs-api-key");
+    fetch(root.getAttribute("data-document"), { headers: key ? { "X-API-Key": key } : {} })
+      .then(function (response) {
+        if (response.status === 401 || response.status === 403) {
+          sessionStorage.removeItem("docs-api-key");
+          askForKey(key
+            ? "That key cannot read the API document. Enter a key with the read scope."
+            : "The API document requires an API key with the read scope.");
+          return;
+        }
+        if (!response.ok) {
+          throw new Error(response.status + " " + response.statusText);
+        }
+        return response.json().then(function (loaded) {
+          doc = loaded;
+          render();
+        });
+      })
+      .catch(function (err) {
+        root.textContent = "";
+        root.appendChild(el("p", { text: "Could not load the API document: " + err.message }));
+      });
+  }
+
+  load();
+})();
@@ -0,0 +1,47 @@
+// Package openapi serves the OpenAPI document describing the HTTP API and a
+// browsable rendering of it. pkg/client is generated from the same document,
+// so openapi.json must be kept in step with routes.SetupAuthRoutes and the
+// shapes the handlers write.
+package openapi
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed openapi.json
+var document []byte
+
+//go:embed docs.html
+var docs []byte
+
+// assets holds the script and styles docs.html renders the document with,
+// so that /docs needs nothing beyond this service.
+//
+//go:embed assets
+var assets embed.FS
+
+// Handler serves the OpenAPI document.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(document)
+}
+
+// Docs serves an HTML page rendering the document served by Handler.
+func Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docs)
+}
+
+// Assets serves the files docs.html loads. It must be mounted at
+// /docs/assets/.
+func Assets() http.Handler {
+	sub, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err)
+	}
+	return http.StripPrefix("/docs/assets/", http.FileServerFS(sub))
+}
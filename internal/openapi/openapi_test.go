@@ -0,0 +1,31 @@
+package openapi
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestDocumentIsValid(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData(document)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// OpenAPI forbids templated paths that differ only in parameter names,
+	// such as /strings/{id} next to /strings/{string_value}.
+	param := regexp.MustCompile(`\{[^}]*\}`)
+	seen := map[string]string{}
+	for path := range doc.Paths.Map() {
+		template := param.ReplaceAllString(path, "{}")
+		if other, ok := seen[template]; ok {
+			t.Errorf("%s and %s differ only in parameter names", path, other)
+		}
+		seen[template] = path
+	}
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+)
+
+// sweepInterval is how often idle buckets are dropped.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  config.Limit
+}
+
+// refill adds the tokens earned since the bucket was last used.
+func (b *bucket) refill(now time.Time) {
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate)
+	b.last = now
+}
+
+// full reports whether the bucket would be full by now, in which case it
+// can be forgotten and recreated on demand.
+func (b *bucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate >= float64(b.limit.Burst)
+}
+
+// decision is the outcome of taking a token or a quota unit.
+type decision struct {
+	allowed   bool
+	limit     int
+	remaining int
+	// reset is when the limit is fully restored, retryAfter when the next
+	// request will be allowed. retryAfter is zero when allowed.
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+// buckets holds one token bucket per key.
+type buckets struct {
+	mu        sync.Mutex
+	entries   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newBuckets() *buckets {
+	return &buckets{entries: map[string]*bucket{}, lastSweep: time.Now()}
+}
+
+// take removes a token from key's bucket, creating it full if needed.
+func (bs *buckets) take(key string, limit config.Limit, now time.Time) decision {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	b := bs.get(key, limit, now)
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return b.decision(allowed)
+}
+
+// peek reports whether key's bucket has a token, without taking it.
+func (bs *buckets) peek(key string, limit config.Limit, now time.Time) decision {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	b := bs.get(key, limit, now)
+	return b.decision(b.tokens >= 1)
+}
+
+// refund puts back a token taken from key's bucket for a request that was
+// then refused for another reason.
+func (bs *buckets) refund(key string) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if b, ok := bs.entries[key]; ok {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+1)
+	}
+}
+
+// get returns key's bucket, refilled up to now. It must be called with
+// bs.mu held.
+func (bs *buckets) get(key string, limit config.Limit, now time.Time) *bucket {
+	if now.Sub(bs.lastSweep) > sweepInterval {
+		for k, b := range bs.entries {
+			if b.full(now) {
+				delete(bs.entries, k)
+			}
+		}
+		bs.lastSweep = now
+	}
+
+	b, ok := bs.entries[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now, limit: limit}
+		bs.entries[key] = b
+	}
+	b.refill(now)
+	return b
+}
+
+// decision describes the bucket after a request was allowed or not.
+func (b *bucket) decision(allowed bool) decision {
+	d := decision{allowed: allowed, limit: b.limit.Burst, remaining: int(b.tokens)}
+	if !allowed {
+		d.retryAfter = seconds((1 - b.tokens) / b.limit.Rate)
+	}
+	d.reset = seconds((float64(b.limit.Burst) - b.tokens) / b.limit.Rate)
+	return d
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+)
+
+func TestBucketAllowsBurstThenRefills(t *testing.T) {
+	bs := newBuckets()
+	limit := config.Limit{Rate: 2, Burst: 3}
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		d := bs.take("client", limit, now)
+		if !d.allowed {
+			t.Fatalf("request %d of the burst was rejected", i+1)
+		}
+		if d.limit != 3 || d.remaining != 2-i {
+			t.Errorf("request %d: limit %d remaining %d, want 3 and %d", i+1, d.limit, d.remaining, 2-i)
+		}
+	}
+
+	d := bs.take("client", limit, now)
+	if d.allowed {
+		t.Fatal("request past the burst was allowed")
+	}
+	if d.retryAfter != 500*time.Millisecond {
+		t.Errorf("retryAfter = %v, want the 500ms one token takes at 2/s", d.retryAfter)
+	}
+	if d.reset != 1500*time.Millisecond {
+		t.Errorf("reset = %v, want the 1.5s three tokens take at 2/s", d.reset)
+	}
+
+	// Other keys have buckets of their own.
+	if d := bs.take("other", limit, now); !d.allowed {
+		t.Error("a different key was rejected")
+	}
+
+	if d := bs.take("client", limit, now.Add(500*time.Millisecond)); !d.allowed {
+		t.Error("request after a token refilled was rejected")
+	}
+	if d := bs.take("client", limit, now.Add(500*time.Millisecond)); d.allowed {
+		t.Error("second request after one token refilled was allowed")
+	}
+}
+
+func TestBucketRefillIsCappedAtBurst(t *testing.T) {
+	bs := newBuckets()
+	limit := config.Limit{Rate: 10, Burst: 2}
+	now := time.Now()
+
+	bs.take("client", limit, now)
+	now = now.Add(time.Hour)
+
+	allowed := 0
+	for i := 0; i < 5; i++ {
+		if bs.take("client", limit, now).allowed {
+			allowed++
+		}
+	}
+	if allowed != 2 {
+		t.Errorf("%d requests allowed after an idle hour, want the burst of 2", allowed)
+	}
+}
+
+func TestBucketsSweepFullBuckets(t *testing.T) {
+	bs := newBuckets()
+	limit := config.Limit{Rate: 1, Burst: 5}
+	now := time.Now()
+	bs.lastSweep = now
+
+	bs.take("idle", limit, now)
+	for i := 0; i < 5; i++ {
+		bs.take("busy", limit, now.Add(sweepInterval))
+	}
+
+	// By the next sweep the idle bucket has refilled and is dropped, while
+	// the busy one is still empty and kept.
+	bs.take("busy", limit, now.Add(sweepInterval+time.Second+time.Millisecond))
+	if _, ok := bs.entries["idle"]; ok {
+		t.Error("the full bucket was not swept")
+	}
+	if _, ok := bs.entries["busy"]; !ok {
+		t.Error("a bucket that was not full was swept")
+	}
+}
+
+func TestQuotaResetsAtUTCMidnight(t *testing.T) {
+	q := newQuotas()
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if d := q.take("client", 2, now); !d.allowed || d.remaining != 1-i {
+			t.Fatalf("request %d: allowed %v remaining %d", i+1, d.allowed, d.remaining)
+		}
+	}
+	d := q.take("client", 2, now)
+	if d.allowed || d.retryAfter != time.Hour {
+		t.Errorf("over quota: allowed %v retryAfter %v, want rejected until midnight", d.allowed, d.retryAfter)
+	}
+
+	if d := q.take("client", 2, now.Add(time.Hour)); !d.allowed {
+		t.Error("request on the next day was rejected")
+	}
+}
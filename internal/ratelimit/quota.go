@@ -0,0 +1,41 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// quotas counts requests per key over the current UTC day.
+type quotas struct {
+	mu     sync.Mutex
+	day    time.Time
+	counts map[string]int
+}
+
+func newQuotas() *quotas {
+	return &quotas{counts: map[string]int{}}
+}
+
+// take counts a request against key's daily quota of limit requests.
+// Rejected requests are not counted.
+func (q *quotas) take(key string, limit int, now time.Time) decision {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !day.Equal(q.day) {
+		q.day, q.counts = day, map[string]int{}
+	}
+	untilReset := day.AddDate(0, 0, 1).Sub(now)
+
+	d := decision{limit: limit, reset: untilReset}
+	if q.counts[key] < limit {
+		q.counts[key]++
+		d.allowed = true
+	} else {
+		d.retryAfter = untilReset
+	}
+	d.remaining = limit - q.counts[key]
+	return d
+}
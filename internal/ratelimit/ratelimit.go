@@ -0,0 +1,193 @@
+// Package ratelimit throttles each client with per-route token buckets and
+// enforces daily request quotas on expensive routes.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/justinndidit/stringAnalyzer/internal/auth"
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/metrics"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+)
+
+type Limiter struct {
+	logger            *zerolog.Logger
+	enabled           bool
+	defaultLimit      config.Limit
+	routes            map[string]config.Limit
+	quotas            map[string]int
+	authFailures      config.Limit
+	trustForwardedFor bool
+
+	buckets *buckets
+	usage   *quotas
+	// failures holds the authentication failures of each IP address.
+	failures *buckets
+}
+
+func NewLimiter(logger *zerolog.Logger, cfg *config.RateLimitConfig) *Limiter {
+	return &Limiter{
+		logger:            logger,
+		enabled:           cfg.Enabled,
+		defaultLimit:      config.Limit{Rate: cfg.Rate, Burst: cfg.Burst},
+		routes:            cfg.RouteLimits,
+		quotas:            cfg.RouteQuotas,
+		authFailures:      cfg.AuthFailureLimit,
+		trustForwardedFor: cfg.TrustForwardedFor,
+		buckets:           newBuckets(),
+		usage:             newQuotas(),
+		failures:          newBuckets(),
+	}
+}
+
+// Limit rejects requests over the client's limit for the matched route with
+// 429 and a Retry-After header, and reports the remaining allowance in
+// RateLimit-* headers. It must run after Authenticate, so that clients are
+// told apart by credential rather than IP, and after chi has matched the
+// route, i.e. in a Group or With rather than on the root router.
+func (l *Limiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		route := r.Method + " " + unversioned(chi.RouteContext(r.Context()).RoutePattern())
+		client := l.client(r)
+		now := time.Now()
+
+		limit, ok := l.routes[route]
+		if !ok {
+			limit = l.defaultLimit
+		}
+		key := route + "|" + client
+		d := l.buckets.take(key, limit, now)
+		policy := fmt.Sprintf("%d;w=%d", limit.Burst, int(math.Ceil(float64(limit.Burst)/limit.Rate)))
+		reason := "rate"
+
+		if quota, ok := l.quotas[route]; ok && d.allowed {
+			qd := l.usage.take(key, quota, now)
+			policy += fmt.Sprintf(", %d;w=%d", quota, 24*60*60)
+			if !qd.allowed {
+				// A request refused for the day must not also cost the
+				// client a token it could use once the quota resets.
+				l.buckets.refund(key)
+			}
+			if !qd.allowed || qd.remaining < d.remaining {
+				d, reason = qd, "quota"
+			}
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Policy", policy)
+		h.Set("RateLimit-Limit", strconv.Itoa(d.limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(d.remaining))
+		h.Set("RateLimit-Reset", ceilSeconds(d.reset))
+
+		if !d.allowed {
+			metrics.RecordRateLimited(route, reason)
+			requestctx.Logger(r.Context(), l.logger).Warn().
+				Str("client", client).Str("reason", reason).Msg("request rate limited")
+
+			h.Set("Retry-After", ceilSeconds(d.retryAfter))
+			problem := errs.NewProblem(errs.CodeRateLimited, "Too many requests, slow down")
+			if reason == "quota" {
+				problem = errs.NewProblem(errs.CodeQuotaExceeded, fmt.Sprintf("Daily quota of %d requests exceeded", d.limit))
+			}
+			util.WriteProblem(w, r, problem)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitAuthFailures throttles credential guessing, which Limit cannot see
+// because it runs after Authenticate. Every 401 takes a token from the
+// client IP's bucket, and once it is empty the IP's requests are refused
+// with 429 before their credentials are checked. Requests that
+// authenticate cost nothing. It must run before Authenticate.
+func (l *Limiter) LimitAuthFailures(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.enabled {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := l.clientIP(r)
+		if d := l.failures.peek(ip, l.authFailures, time.Now()); !d.allowed {
+			metrics.RecordRateLimited("*", "auth")
+			requestctx.Logger(r.Context(), l.logger).Warn().
+				Str("client", ip).Str("reason", "auth").Msg("request rate limited")
+
+			w.Header().Set("Retry-After", ceilSeconds(d.retryAfter))
+			util.WriteProblem(w, r, errs.NewProblem(errs.CodeRateLimited, "Too many failed authentication attempts, slow down"))
+			return
+		}
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		if ww.Status() == http.StatusUnauthorized {
+			l.failures.take(ip, l.authFailures, time.Now())
+		}
+	})
+}
+
+// client identifies the caller by its authenticated subject, falling back
+// to its IP address.
+func (l *Limiter) client(r *http.Request) string {
+	if principal := auth.PrincipalFrom(r.Context()); principal != nil {
+		return "subject:" + principal.Subject
+	}
+	return l.clientIP(r)
+}
+
+// clientIP identifies the caller by its IP address.
+func (l *Limiter) clientIP(r *http.Request) string {
+	if l.trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			ip, _, _ := strings.Cut(forwarded, ",")
+			return "ip:" + strings.TrimSpace(ip)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// unversioned strips the API version from a route pattern, so that every
+// version of a route, and its deprecated unversioned alias, shares the
+// configured limits and the client's allowance: "/v1/strings" and
+// "/strings" are both "/strings".
+func unversioned(pattern string) string {
+	rest, ok := strings.CutPrefix(pattern, "/v")
+	if !ok {
+		return pattern
+	}
+
+	version, path, _ := strings.Cut(rest, "/")
+	if _, err := strconv.Atoi(version); err != nil {
+		return pattern
+	}
+	return "/" + path
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,88 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/rs/zerolog"
+)
+
+func newTestLimiter(cfg config.RateLimitConfig) *Limiter {
+	logger := zerolog.Nop()
+	cfg.Enabled = true
+	return NewLimiter(&logger, &cfg)
+}
+
+func serve(h http.Handler, method, path, ip string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = ip + ":1234"
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestQuotaRefusalKeepsTheToken(t *testing.T) {
+	l := newTestLimiter(config.RateLimitConfig{
+		Rate:        0.001,
+		Burst:       5,
+		RouteQuotas: map[string]int{"GET /strings": 2},
+	})
+	r := chi.NewRouter()
+	r.With(l.Limit).Get("/v1/strings", func(w http.ResponseWriter, r *http.Request) {})
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests} {
+		if rec := serve(r, http.MethodGet, "/v1/strings", "192.0.2.1"); rec.Code != want {
+			t.Fatalf("request %d: status %d, want %d", i+1, rec.Code, want)
+		}
+	}
+
+	// Only the two requests the quota allowed took a token.
+	if tokens := l.buckets.entries["GET /strings|ip:192.0.2.1"].tokens; tokens < 3 || tokens >= 4 {
+		t.Errorf("%v tokens left, want 3", tokens)
+	}
+}
+
+func TestLimitAuthFailures(t *testing.T) {
+	l := newTestLimiter(config.RateLimitConfig{
+		Rate:             10,
+		Burst:            10,
+		AuthFailureLimit: config.Limit{Rate: 0.001, Burst: 2},
+	})
+	h := l.LimitAuthFailures(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != "valid" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+
+	// Requests that authenticate cost nothing.
+	for i := 0; i < 5; i++ {
+		if rec := serve(h, http.MethodGet, "/v1/strings", "192.0.2.1", "X-API-Key", "valid"); rec.Code != http.StatusOK {
+			t.Fatalf("authenticated request %d: status %d", i+1, rec.Code)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := serve(h, http.MethodGet, "/v1/strings", "192.0.2.1", "X-API-Key", "guess"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("failed attempt %d: status %d, want 401", i+1, rec.Code)
+		}
+	}
+
+	// Once the failures are used up, the IP is refused before its
+	// credentials are checked, whatever they are.
+	for _, key := range []string{"guess", "valid"} {
+		rec := serve(h, http.MethodGet, "/v1/strings", "192.0.2.1", "X-API-Key", key)
+		if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+			t.Errorf("key %q after the failures: status %d Retry-After %q, want 429 with Retry-After", key, rec.Code, rec.Header().Get("Retry-After"))
+		}
+	}
+
+	if rec := serve(h, http.MethodGet, "/v1/strings", "192.0.2.2", "X-API-Key", "guess"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("another IP: status %d, want 401", rec.Code)
+	}
+}
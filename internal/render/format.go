@@ -0,0 +1,116 @@
+// Package render encodes list and object responses in the format a client
+// negotiates: JSON, CSV, NDJSON or MessagePack. Lists are written row by row
+// as they are produced rather than built in memory first.
+package render
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+)
+
+// Format is an encoding responses can be served in. Its value is what the
+// format query parameter selects it with.
+type Format string
+
+const (
+	JSON        Format = "json"
+	CSV         Format = "csv"
+	NDJSON      Format = "ndjson"
+	MessagePack Format = "msgpack"
+)
+
+// formats lists every Format in order of preference with the media types
+// that select it. The first media type is the one responses are served as.
+var formats = []struct {
+	format     Format
+	mediaTypes []string
+}{
+	{JSON, []string{"application/json"}},
+	{CSV, []string{"text/csv"}},
+	{NDJSON, []string{"application/x-ndjson", "application/ndjson"}},
+	{MessagePack, []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}},
+}
+
+// ContentType is the Content-Type of responses in f.
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	case MessagePack:
+		return "application/msgpack"
+	default:
+		return "application/json"
+	}
+}
+
+// Negotiate picks the format of the response to r: the one its format query
+// parameter names, else the most preferred one its Accept header allows,
+// else JSON. It returns errs.ErrUnknownFormat when the parameter names no
+// format and errs.ErrNotAcceptable when Accept rules out every format.
+func Negotiate(r *http.Request) (Format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		for _, f := range formats {
+			if string(f.format) == strings.ToLower(name) {
+				return f.format, nil
+			}
+		}
+		return "", errs.ErrUnknownFormat
+	}
+
+	accept := r.Header.Get("Accept")
+	if strings.TrimSpace(accept) == "" {
+		return JSON, nil
+	}
+
+	best, bestQ := Format(""), 0.0
+	for _, f := range formats {
+		if q := quality(accept, f.mediaTypes); q > bestQ {
+			best, bestQ = f.format, q
+		}
+	}
+	if best == "" {
+		return "", errs.ErrNotAcceptable
+	}
+	return best, nil
+}
+
+// quality returns the highest q value accept gives any of mediaTypes,
+// counting wildcards such as "text/*" and "*/*", or 0 when it allows none.
+func quality(accept string, mediaTypes []string) float64 {
+	best := 0.0
+	for _, entry := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(entry))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		for _, mediaType := range mediaTypes {
+			if matches(mediaRange, mediaType) && q > best {
+				best = q
+			}
+		}
+	}
+	return best
+}
+
+func matches(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	rangeType, rangeSubtype, _ := strings.Cut(mediaRange, "/")
+	typ, _, _ := strings.Cut(mediaType, "/")
+	return rangeSubtype == "*" && rangeType == typ
+}
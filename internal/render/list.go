@@ -0,0 +1,288 @@
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// flushEvery is how many rows a List writes between flushes, so that
+// clients see rows arrive without a flush per row.
+const flushEvery = 100
+
+// ListHeader describes a list response before its rows are written.
+type ListHeader struct {
+	// Count is the number of rows that will follow.
+	Count int
+	// Members are the JSON and MessagePack envelope's members besides count
+	// and data, e.g. filters_applied.
+	Members map[string]any
+	// Columns are the CSV columns, each the dotted path of a row value such
+	// as "properties.length" and headed with the path's last element.
+	Columns []string
+}
+
+// List writes a list response. JSON and MessagePack wrap the rows in an
+// object with the count and the header's members; CSV and NDJSON carry the
+// rows alone, one per line.
+//
+// The status is sent before any row is written, so a failure part way
+// through can only be logged: the client sees a truncated body.
+type List struct {
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	format Format
+	header ListHeader
+	rows   int
+	err    error
+
+	csv     *csv.Writer
+	msgpack *msgpack.Encoder
+}
+
+// StartList sends the status and headers of a list response in format and
+// writes what precedes its rows.
+func StartList(w http.ResponseWriter, format Format, status int, header ListHeader) *List {
+	l := &List{w: w, rc: http.NewResponseController(w), format: format, header: header}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+
+	switch format {
+	case JSON:
+		l.write([]byte("{\n\"count\": "))
+		l.writeJSON(header.Count)
+		l.write([]byte(",\n\"data\": ["))
+
+	case CSV:
+		l.csv = csv.NewWriter(w)
+		l.fail(l.csv.Write(columnHeaders(header.Columns)))
+
+	case MessagePack:
+		l.msgpack = newMsgpackEncoder(w)
+		l.fail(l.msgpack.EncodeMapLen(2 + len(header.Members)))
+		l.fail(l.msgpack.EncodeString("count"))
+		l.fail(l.msgpack.EncodeInt(int64(header.Count)))
+		l.fail(l.msgpack.EncodeString("data"))
+		l.fail(l.msgpack.EncodeArrayLen(header.Count))
+	}
+	return l
+}
+
+// Row writes the next row. It returns the first error the list ran into,
+// after which every call is a no-op.
+func (l *List) Row(row map[string]any) error {
+	if l.err != nil {
+		return l.err
+	}
+	if l.rows == l.header.Count {
+		l.err = errors.New("render: more rows than the list's count")
+		return l.err
+	}
+
+	switch l.format {
+	case JSON:
+		if l.rows > 0 {
+			l.write([]byte(","))
+		}
+		l.write([]byte("\n"))
+		l.writeJSON(row)
+
+	case NDJSON:
+		js, err := json.Marshal(row)
+		l.fail(err)
+		l.write(append(js, '\n'))
+
+	case CSV:
+		record, err := csvRecord(row, l.header.Columns)
+		l.fail(err)
+		if l.err == nil {
+			l.fail(l.csv.Write(record))
+		}
+
+	case MessagePack:
+		l.fail(l.msgpack.Encode(row))
+	}
+
+	l.rows++
+	if l.rows%flushEvery == 0 {
+		l.flush()
+	}
+	return l.err
+}
+
+// End writes what follows the rows and flushes the response.
+func (l *List) End() error {
+	if l.err != nil {
+		return l.err
+	}
+	if l.rows != l.header.Count {
+		l.err = errors.New("render: fewer rows than the list's count")
+		return l.err
+	}
+
+	names := make([]string, 0, len(l.header.Members))
+	for name := range l.header.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	switch l.format {
+	case JSON:
+		if l.rows > 0 {
+			l.write([]byte("\n"))
+		}
+		l.write([]byte("]"))
+		for _, name := range names {
+			l.write([]byte(",\n"))
+			l.writeJSON(name)
+			l.write([]byte(": "))
+			l.writeJSON(l.header.Members[name])
+		}
+		l.write([]byte("\n}\n"))
+
+	case MessagePack:
+		for _, name := range names {
+			l.fail(l.msgpack.EncodeString(name))
+			l.fail(l.msgpack.Encode(l.header.Members[name]))
+		}
+	}
+
+	l.flush()
+	return l.err
+}
+
+// Write writes a single object in format. CSV renders it as one row under
+// a header of columns; NDJSON as one line.
+func Write(w http.ResponseWriter, format Format, status int, object map[string]any, columns []string) error {
+	switch format {
+	case CSV, NDJSON:
+		l := StartList(w, format, status, ListHeader{Count: 1, Columns: columns})
+		l.Row(object)
+		return l.End()
+
+	case MessagePack:
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Add("Vary", "Accept")
+		w.WriteHeader(status)
+		return newMsgpackEncoder(w).Encode(object)
+
+	default:
+		js, err := json.MarshalIndent(object, "", "")
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Add("Vary", "Accept")
+		w.WriteHeader(status)
+		_, err = w.Write(append(js, '\n'))
+		return err
+	}
+}
+
+func (l *List) write(p []byte) {
+	if l.err == nil {
+		_, l.err = l.w.Write(p)
+	}
+}
+
+// writeJSON writes v the way util.WriteJson does: indented with no indent,
+// which puts every member and element on a line of its own.
+func (l *List) writeJSON(v any) {
+	js, err := json.MarshalIndent(v, "", "")
+	l.fail(err)
+	l.write(js)
+}
+
+func (l *List) fail(err error) {
+	if l.err == nil {
+		l.err = err
+	}
+}
+
+func (l *List) flush() {
+	if l.csv != nil {
+		l.csv.Flush()
+		l.fail(l.csv.Error())
+	}
+	if err := l.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		l.fail(err)
+	}
+}
+
+// newMsgpackEncoder returns an encoder that names struct fields as
+// encoding/json does, so MessagePack and JSON responses have the same keys.
+func newMsgpackEncoder(w http.ResponseWriter) *msgpack.Encoder {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	enc.SetSortMapKeys(true)
+	return enc
+}
+
+func columnHeaders(columns []string) []string {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column[strings.LastIndex(column, ".")+1:]
+	}
+	return headers
+}
+
+// csvRecord picks the columns out of row. Strings are written as they are,
+// guarded by csvText, missing and null values as empty cells, and anything
+// else, including lists and objects, as JSON.
+func csvRecord(row map[string]any, columns []string) ([]string, error) {
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		value := lookup(row, column)
+		if s, ok := value.(string); ok {
+			record[i] = csvText(s)
+			continue
+		}
+
+		js, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case string(js) == "null":
+		case js[0] == '"':
+			if err := json.Unmarshal(js, &record[i]); err != nil {
+				return nil, err
+			}
+			record[i] = csvText(record[i])
+		default:
+			record[i] = string(js)
+		}
+	}
+	return record, nil
+}
+
+// csvText prefixes s with an apostrophe when it starts with a character
+// spreadsheets read as the start of a formula, so that opening an export
+// cannot run a stored value as one.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// lookup returns the value at the dotted path in row, or nil.
+func lookup(row map[string]any, path string) any {
+	var value any = row
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
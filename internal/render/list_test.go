@@ -0,0 +1,55 @@
+package render
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVNeutralisesFormulas(t *testing.T) {
+	rows := []map[string]any{
+		{"value": "=HYPERLINK(\"http://evil.example\")", "properties": map[string]any{"length": 31}},
+		{"value": "+1", "properties": map[string]any{"length": -2}},
+		{"value": "-2+3"},
+		{"value": "@SUM(A1)"},
+		{"value": "\tTAB"},
+		{"value": "\rCR"},
+		{"value": "plain = text", "tags": []string{"=x"}},
+		{"value": ""},
+	}
+
+	rec := httptest.NewRecorder()
+	l := StartList(rec, CSV, http.StatusOK, ListHeader{Count: len(rows), Columns: []string{"value", "properties.length", "tags"}})
+	for _, row := range rows {
+		if err := l.Row(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.End(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"value", "length", "tags"},
+		{"'=HYPERLINK(\"http://evil.example\")", "31", ""},
+		// Numbers are written as JSON and left alone.
+		{"'+1", "-2", ""},
+		{"'-2+3", "", ""},
+		{"'@SUM(A1)", "", ""},
+		{"'\tTAB", "", ""},
+		{"'\rCR", "", ""},
+		// Lists are JSON, which cannot start a formula.
+		{"plain = text", "", `["=x"]`},
+		{"", "", ""},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %q, want %q", records, want)
+	}
+}
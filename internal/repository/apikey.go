@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/database"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/rs/zerolog"
+)
+
+// apiKeyColumns lists the columns that map onto model.APIKey. The key hash is
+// never read back.
+const apiKeyColumns = `id, name, prefix, scopes, created_at, revoked_at`
+
+type APIKeyRepository struct {
+	logger *zerolog.Logger
+	db     *database.Database
+}
+
+func NewAPIKeyRepository(logger *zerolog.Logger, db *database.Database) *APIKeyRepository {
+	return &APIKeyRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	rows, err := r.db.Pool.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List api keys query failed!")
+		return nil, fmt.Errorf("failed to execute list api keys query: %w", err)
+	}
+
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.APIKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:api_keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *APIKeyRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	stmt := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = @key_hash AND revoked_at IS NULL`
+
+	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"key_hash": hash,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute get api key query: %w", err)
+	}
+
+	key, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.APIKey])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to collect row from table:api_keys: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, payload *dto.StoreAPIKey) (*model.APIKey, error) {
+	stmt := `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes)
+		VALUES (@name, @prefix, @key_hash, @scopes)
+		RETURNING ` + apiKeyColumns
+
+	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"name":     payload.Name,
+		"prefix":   payload.Prefix,
+		"key_hash": payload.Hash,
+		"scopes":   payload.Scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute create api key query: %w", err)
+	}
+
+	key, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.APIKey])
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Create api key query failed!")
+		return nil, fmt.Errorf("failed to collect row from table:api_keys: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id int64) error {
+	stmt := `
+		UPDATE api_keys
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = @id AND revoked_at IS NULL
+	`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"id": id,
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Revoke api key query failed!")
+		return fmt.Errorf("failed to execute revoke api key query: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return errs.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/database"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/rs/zerolog"
+)
+
+// snapshotColumn renders a strings row as JSON for the audit log, leaving out
+// the derived search vector.
+const snapshotColumn = `to_jsonb(strings) - 'search_vector'`
+
+// auditEntry is a mutation waiting to be appended to audit_events. The actor
+// and request ID come from the request context.
+type auditEntry struct {
+	Namespace string
+	Operation string
+	Hash      string
+	Before    []byte
+	After     []byte
+}
+
+func recordAudit(ctx context.Context, tx pgx.Tx, entry auditEntry) error {
+	stmt := `
+		INSERT INTO audit_events (namespace, actor, request_id, operation, sha256_hash, before, after)
+		VALUES (@namespace, @actor, @request_id, @operation, @sha256_hash, @before::jsonb, @after::jsonb)
+	`
+
+	_, err := tx.Exec(ctx, stmt, pgx.NamedArgs{
+		"namespace":   entry.Namespace,
+		"actor":       requestctx.Actor(ctx),
+		"request_id":  nullable(requestctx.RequestID(ctx)),
+		"operation":   entry.Operation,
+		"sha256_hash": nullable(entry.Hash),
+		"before":      nullableJSON(entry.Before),
+		"after":       nullableJSON(entry.After),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// snapshotString locks a string row and returns it as JSON. condition narrows
+// the rows considered, e.g. to live or trashed strings. It returns
+// errs.ErrNotFound when no row matches.
+func snapshotString(ctx context.Context, tx pgx.Tx, namespace string, hash string, condition string) ([]byte, error) {
+	stmt := `
+		SELECT ` + snapshotColumn + `
+		FROM strings
+		WHERE namespace = @namespace AND sha256_hash = @sha256_hash AND ` + condition + `
+		FOR UPDATE
+	`
+
+	var snapshot []byte
+	err := tx.QueryRow(ctx, stmt, pgx.NamedArgs{
+		"namespace":   namespace,
+		"sha256_hash": hash,
+	}).Scan(&snapshot)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to snapshot string: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+func nullable(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func nullableJSON(b []byte) any {
+	if b == nil {
+		return nil
+	}
+	return string(b)
+}
+
+type AuditRepository struct {
+	logger *zerolog.Logger
+	db     *database.Database
+}
+
+func NewAuditRepository(logger *zerolog.Logger, db *database.Database) *AuditRepository {
+	return &AuditRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// ListAuditEvents returns matching events, newest first. Pagination is keyed
+// on the event ID: pass the last ID of a page as params.Cursor to get the next.
+func (r *AuditRepository) ListAuditEvents(ctx context.Context, params dto.AuditQuery) ([]model.AuditEvent, error) {
+	stmt := `
+		SELECT
+			id,
+			occurred_at,
+			namespace,
+			actor,
+			request_id,
+			operation,
+			sha256_hash,
+			before,
+			after
+		FROM
+			audit_events
+		WHERE
+			(@namespace::text IS NULL OR namespace = @namespace::text)
+			AND (@actor::text IS NULL OR actor = @actor::text)
+			AND (@operation::text IS NULL OR operation = @operation::text)
+			AND (@sha256_hash::text IS NULL OR sha256_hash = @sha256_hash::text)
+			AND (@request_id::text IS NULL OR request_id = @request_id::text)
+			AND (@since::timestamptz IS NULL OR occurred_at >= @since::timestamptz)
+			AND (@until::timestamptz IS NULL OR occurred_at < @until::timestamptz)
+			AND (@cursor::bigint IS NULL OR id < @cursor::bigint)
+		ORDER BY
+			id DESC
+		LIMIT @limit
+	`
+
+	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"namespace":   nullable(params.Namespace),
+		"actor":       nullable(params.Actor),
+		"operation":   nullable(params.Operation),
+		"sha256_hash": nullable(params.StringID),
+		"request_id":  nullable(params.RequestID),
+		"since":       params.Since,
+		"until":       params.Until,
+		"cursor":      params.Cursor,
+		"limit":       params.Limit,
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List audit events query failed!")
+		return nil, fmt.Errorf("failed to execute list audit events query: %w", err)
+	}
+
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.AuditEvent])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:audit_events: %w", err)
+	}
+
+	return events, nil
+}
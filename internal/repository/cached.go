@@ -0,0 +1,285 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/cache"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+)
+
+// CachedStore is a read-through cache in front of a StringStore and its
+// NamespaceStore. Lookups by value and filtered queries with up to
+// maxCachedRows results are cached; every mutation invalidates what it could
+// have changed. Entries holding a string that has expired are dropped when
+// read, so they never outlive a string's TTL.
+//
+// Cache keys are laid out as "<namespace>\x00string\x00<hash>" and
+// "<namespace>\x00query\x00<kind>\x00<params>", so a namespace or all of its
+// queries can be dropped by prefix.
+type CachedStore struct {
+	StringStore
+	NamespaceStore
+
+	logger *zerolog.Logger
+	cache  cache.Cache
+
+	// generations counts invalidations per namespace. A read only fills the
+	// cache if no invalidation ran while it was querying the store, so a
+	// slow read cannot put back a value a concurrent write just removed.
+	mu          sync.Mutex
+	generations map[string]uint64
+}
+
+func NewCachedStore(logger *zerolog.Logger, strings StringStore, namespaces NamespaceStore, c cache.Cache) *CachedStore {
+	return &CachedStore{
+		StringStore:    strings,
+		NamespaceStore: namespaces,
+		logger:         logger,
+		cache:          c,
+		generations:    map[string]uint64{},
+	}
+}
+
+func stringCacheKey(namespace string, hash string) string {
+	return namespace + "\x00string\x00" + hash
+}
+
+func queryCachePrefix(namespace string) string {
+	return namespace + "\x00query\x00"
+}
+
+// queryCacheKey keys a query by its parameters and selection, since results
+// computed for a narrower selection leave out parts a wider one needs.
+func queryCacheKey(namespace string, kind string, params any, selection Selection) (string, error) {
+	encoded, err := json.Marshal([]any{params, selection})
+	if err != nil {
+		return "", err
+	}
+	return queryCachePrefix(namespace) + kind + "\x00" + string(encoded), nil
+}
+
+func (s *CachedStore) generation(namespace string) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generations[namespace]
+}
+
+// fill caches value under key unless namespace was invalidated since gen.
+func (s *CachedStore) fill(namespace string, gen uint64, key string, value any) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("could not encode cache entry")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.generations[namespace] == gen {
+		s.cache.Set(key, encoded)
+	}
+}
+
+// lookup decodes the value cached under key into target.
+func (s *CachedStore) lookup(key string, target any) bool {
+	encoded, ok := s.cache.Get(key)
+	if !ok {
+		return false
+	}
+
+	if err := json.Unmarshal(encoded, target); err != nil {
+		s.logger.Warn().Err(err).Msg("could not decode cache entry")
+		s.cache.Delete(key)
+		return false
+	}
+	return true
+}
+
+// invalidate drops the cached string with the given hash, if any, and every
+// cached query in the namespace.
+func (s *CachedStore) invalidate(namespace string, hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generations[namespace]++
+	if hash != "" {
+		s.cache.Delete(stringCacheKey(namespace, hash))
+	}
+	s.cache.DeletePrefix(queryCachePrefix(namespace))
+}
+
+// invalidateNamespace drops everything cached for the namespace.
+func (s *CachedStore) invalidateNamespace(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generations[namespace]++
+	s.cache.DeletePrefix(namespace + "\x00")
+}
+
+func (s *CachedStore) GetStringByValue(ctx context.Context, namespace string, value string) (*model.String, error) {
+	key := stringCacheKey(namespace, util.Hash(value))
+
+	// A cached null records that the value is not stored, which saves the
+	// round trip UploadString makes before every insert.
+	var record *model.String
+	if s.lookup(key, &record) && (record == nil || !isExpired(record, time.Now())) {
+		return record, nil
+	}
+
+	gen := s.generation(namespace)
+	record, err := s.StringStore.GetStringByValue(ctx, namespace, value)
+	if err != nil {
+		return nil, err
+	}
+
+	s.fill(namespace, gen, key, record)
+	return record, nil
+}
+
+func (s *CachedStore) GetFilteredStrings(ctx context.Context, namespace string, params dto.QueryParams, selection Selection, start func(ResultSummary) error, row func(*model.StringMatch) error) error {
+	query := func(start func(ResultSummary) error, row func(*model.StringMatch) error) error {
+		return s.StringStore.GetFilteredStrings(ctx, namespace, params, selection, start, row)
+	}
+
+	key, err := queryCacheKey(namespace, "filter", params, selection)
+	if err != nil {
+		return query(start, row)
+	}
+	return streamCached(s, namespace, key, func(match *model.StringMatch) *model.String { return &match.String }, query, start, row)
+}
+
+func (s *CachedStore) GetFilteredStringsByNaturalLanguage(ctx context.Context, namespace string, params *dto.FilterParams, selection Selection, start func(ResultSummary) error, row func(*model.String) error) error {
+	query := func(start func(ResultSummary) error, row func(*model.String) error) error {
+		return s.StringStore.GetFilteredStringsByNaturalLanguage(ctx, namespace, params, selection, start, row)
+	}
+
+	key, err := queryCacheKey(namespace, "natural_language", params, selection)
+	if err != nil {
+		return query(start, row)
+	}
+	return streamCached(s, namespace, key, func(record *model.String) *model.String { return record }, query, start, row)
+}
+
+// maxCachedRows caps the size of the query results that are cached. Larger
+// ones stream through without being held in memory.
+const maxCachedRows = 1000
+
+// cachedResult is a streamed query result as it is cached.
+type cachedResult[T any] struct {
+	Summary ResultSummary
+	Rows    []T
+}
+
+// streamCached replays the result cached under key, or runs query and
+// caches its result on the way through if it is small enough. A cached
+// result in which a string has since expired is read again, because
+// dropping the string would leave its summary wrong.
+func streamCached[T any](s *CachedStore, namespace string, key string, stringOf func(*T) *model.String,
+	query func(start func(ResultSummary) error, row func(*T) error) error,
+	start func(ResultSummary) error, row func(*T) error,
+) error {
+	var cached cachedResult[T]
+	if s.lookup(key, &cached) {
+		now := time.Now()
+		live := true
+		for i := range cached.Rows {
+			if isExpired(stringOf(&cached.Rows[i]), now) {
+				live = false
+				break
+			}
+		}
+		if live {
+			return streamResults(cached.Summary, cached.Rows, start, row)
+		}
+	}
+
+	gen := s.generation(namespace)
+	var result *cachedResult[T]
+	err := query(
+		func(summary ResultSummary) error {
+			if summary.Count <= maxCachedRows {
+				result = &cachedResult[T]{Summary: summary, Rows: make([]T, 0, summary.Count)}
+			}
+			return start(summary)
+		},
+		func(record *T) error {
+			if result != nil {
+				result.Rows = append(result.Rows, *record)
+			}
+			return row(record)
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result != nil {
+		s.fill(namespace, gen, key, result)
+	}
+	return nil
+}
+
+// The mutations below invalidate even when the store returns an error: a
+// failed create may still mean a cached "not found" was wrong.
+
+func (s *CachedStore) CreateString(ctx context.Context, namespace string, payload *dto.CreateString) (*model.String, error) {
+	defer s.invalidate(namespace, payload.Hash)
+	return s.StringStore.CreateString(ctx, namespace, payload)
+}
+
+func (s *CachedStore) PatchMetadata(ctx context.Context, namespace string, hash string, patch any, precondition Precondition) (*model.String, error) {
+	defer s.invalidate(namespace, hash)
+	return s.StringStore.PatchMetadata(ctx, namespace, hash, patch, precondition)
+}
+
+func (s *CachedStore) DeleteString(ctx context.Context, namespace string, value string, hard bool, precondition Precondition) error {
+	defer s.invalidate(namespace, util.Hash(value))
+	return s.StringStore.DeleteString(ctx, namespace, value, hard, precondition)
+}
+
+func (s *CachedStore) AddTags(ctx context.Context, namespace string, hash string, tags []string) ([]string, error) {
+	defer s.invalidate(namespace, hash)
+	return s.StringStore.AddTags(ctx, namespace, hash, tags)
+}
+
+func (s *CachedStore) RemoveTag(ctx context.Context, namespace string, hash string, tag string) error {
+	defer s.invalidate(namespace, hash)
+	return s.StringStore.RemoveTag(ctx, namespace, hash, tag)
+}
+
+// DeleteCollection changes the collections of every member, so it drops the
+// whole namespace.
+func (s *CachedStore) DeleteCollection(ctx context.Context, namespace string, name string) error {
+	defer s.invalidateNamespace(namespace)
+	return s.StringStore.DeleteCollection(ctx, namespace, name)
+}
+
+func (s *CachedStore) AddToCollection(ctx context.Context, namespace string, name string, hash string) error {
+	defer s.invalidate(namespace, hash)
+	return s.StringStore.AddToCollection(ctx, namespace, name, hash)
+}
+
+func (s *CachedStore) RemoveFromCollection(ctx context.Context, namespace string, name string, hash string) error {
+	defer s.invalidate(namespace, hash)
+	return s.StringStore.RemoveFromCollection(ctx, namespace, name, hash)
+}
+
+func (s *CachedStore) RestoreString(ctx context.Context, namespace string, hash string) (*model.String, error) {
+	defer s.invalidate(namespace, hash)
+	return s.StringStore.RestoreString(ctx, namespace, hash)
+}
+
+// PurgeDeleted and ReapExpired need no invalidation: trashed strings are never
+// cached, and expired ones are filtered out on read.
+
+func (s *CachedStore) DeleteNamespace(ctx context.Context, name string) error {
+	defer s.invalidateNamespace(name)
+	return s.NamespaceStore.DeleteNamespace(ctx, name)
+}
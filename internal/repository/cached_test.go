@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/cache"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+)
+
+// countingStore is a MemoryStore that counts the reads reaching it, so a
+// test can tell a cache hit from a miss.
+type countingStore struct {
+	*MemoryStore
+
+	lookups int
+	queries int
+	// duringQuery, if set, runs once while a filtered query is reading.
+	duringQuery func()
+}
+
+func (s *countingStore) GetStringByValue(ctx context.Context, namespace string, value string) (*model.String, error) {
+	s.lookups++
+	return s.MemoryStore.GetStringByValue(ctx, namespace, value)
+}
+
+func (s *countingStore) GetFilteredStrings(ctx context.Context, namespace string, params dto.QueryParams, selection Selection, start func(ResultSummary) error, row func(*model.StringMatch) error) error {
+	s.queries++
+	if hook := s.duringQuery; hook != nil {
+		s.duringQuery = nil
+		hook()
+	}
+	return s.MemoryStore.GetFilteredStrings(ctx, namespace, params, selection, start, row)
+}
+
+func newTestCachedStore(t *testing.T) (*CachedStore, *countingStore) {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	store := &countingStore{MemoryStore: NewMemoryStore(&logger)}
+	return NewCachedStore(&logger, store, store.MemoryStore, cache.NewLRU(100, time.Minute)), store
+}
+
+func createTestString(t *testing.T, s StringStore, value string, expiresAt *time.Time) {
+	t.Helper()
+
+	_, err := s.CreateString(context.Background(), DefaultNamespace, &dto.CreateString{
+		StringValue: value,
+		Hash:        util.Hash(value),
+		Length:      len(value),
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// query runs an unfiltered query and returns the number of rows streamed.
+func query(t *testing.T, s StringStore) int {
+	t.Helper()
+
+	rows := 0
+	err := s.GetFilteredStrings(context.Background(), DefaultNamespace, dto.QueryParams{}, SelectAll,
+		func(ResultSummary) error { return nil },
+		func(*model.StringMatch) error { rows++; return nil },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestCachedNotFoundIsDroppedOnCreate(t *testing.T) {
+	cached, store := newTestCachedStore(t)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if record, err := cached.GetStringByValue(ctx, DefaultNamespace, "racecar"); err != nil || record != nil {
+			t.Fatalf("lookup %d: %v, %v; want not found", i+1, record, err)
+		}
+	}
+	if store.lookups != 1 {
+		t.Fatalf("%d store lookups, want the not found to be cached", store.lookups)
+	}
+
+	createTestString(t, cached, "racecar", nil)
+
+	record, err := cached.GetStringByValue(ctx, DefaultNamespace, "racecar")
+	if err != nil || record == nil || record.StringValue != "racecar" {
+		t.Fatalf("lookup after create: %v, %v", record, err)
+	}
+	if store.lookups != 2 {
+		t.Errorf("%d store lookups, want the cached not found dropped", store.lookups)
+	}
+}
+
+func TestMutationsInvalidateCachedQueries(t *testing.T) {
+	ctx := context.Background()
+	hash := util.Hash("racecar")
+
+	for _, tc := range []struct {
+		name   string
+		mutate func(s *CachedStore) error
+	}{
+		{"delete", func(s *CachedStore) error {
+			return s.DeleteString(ctx, DefaultNamespace, "racecar", false, nil)
+		}},
+		{"patch", func(s *CachedStore) error {
+			_, err := s.PatchMetadata(ctx, DefaultNamespace, hash, map[string]any{"a": 1}, nil)
+			return err
+		}},
+		{"add tags", func(s *CachedStore) error {
+			_, err := s.AddTags(ctx, DefaultNamespace, hash, []string{"b"})
+			return err
+		}},
+		{"remove tag", func(s *CachedStore) error {
+			return s.RemoveTag(ctx, DefaultNamespace, hash, "a")
+		}},
+	} {
+		cached, store := newTestCachedStore(t)
+		createTestString(t, cached, "racecar", nil)
+		if _, err := store.AddTags(ctx, DefaultNamespace, hash, []string{"a"}); err != nil {
+			t.Fatal(err)
+		}
+
+		query(t, cached)
+		query(t, cached)
+		if store.queries != 1 {
+			t.Fatalf("%s: %d store queries before the change, want the second cached", tc.name, store.queries)
+		}
+
+		if err := tc.mutate(cached); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		query(t, cached)
+		if store.queries != 2 {
+			t.Errorf("%s: %d store queries after the change, want the cached query dropped", tc.name, store.queries)
+		}
+	}
+}
+
+func TestFillRacingWithWriteIsDiscarded(t *testing.T) {
+	cached, store := newTestCachedStore(t)
+	createTestString(t, cached, "racecar", nil)
+
+	// A write lands after the query has read, but before it fills the
+	// cache with what is now a stale result.
+	store.duringQuery = func() { createTestString(t, cached, "level", nil) }
+	if rows := query(t, cached); rows != 2 {
+		t.Fatalf("%d rows, want 2", rows)
+	}
+
+	query(t, cached)
+	if store.queries != 2 {
+		t.Errorf("%d store queries, want the racing fill discarded", store.queries)
+	}
+	query(t, cached)
+	if store.queries != 2 {
+		t.Errorf("%d store queries, want the result cached once no write raced", store.queries)
+	}
+}
+
+func TestExpiredCachedRowsAreReadAgain(t *testing.T) {
+	cached, store := newTestCachedStore(t)
+	expiresAt := time.Now().Add(50 * time.Millisecond)
+	createTestString(t, cached, "racecar", &expiresAt)
+	createTestString(t, cached, "level", nil)
+
+	if rows := query(t, cached); rows != 2 {
+		t.Fatalf("%d rows, want 2", rows)
+	}
+	time.Sleep(time.Until(expiresAt) + 10*time.Millisecond)
+
+	if rows := query(t, cached); rows != 1 {
+		t.Errorf("%d rows after expiry, want 1", rows)
+	}
+	if store.queries != 2 {
+		t.Errorf("%d store queries, want the expired result read again", store.queries)
+	}
+}
+
+func TestLargeResultsAreNotCached(t *testing.T) {
+	cached, store := newTestCachedStore(t)
+	for i := 0; i <= maxCachedRows; i++ {
+		createTestString(t, store.MemoryStore, fmt.Sprintf("string %d", i), nil)
+	}
+
+	for i := 0; i < 2; i++ {
+		if rows := query(t, cached); rows != maxCachedRows+1 {
+			t.Fatalf("%d rows, want %d", rows, maxCachedRows+1)
+		}
+	}
+	if store.queries != 2 {
+		t.Errorf("%d store queries, want a result over maxCachedRows never cached", store.queries)
+	}
+}
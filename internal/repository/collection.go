@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+// Postgres error codes the repositories translate into errs sentinels.
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+)
+
+func (r *StringRepository) ListCollections(ctx context.Context, namespace string) ([]model.Collection, error) {
+	stmt := `
+		SELECT
+			c.name,
+			c.created_at,
+			COUNT(s.sha256_hash)::int AS string_count
+		FROM
+			collections c
+			LEFT JOIN collection_strings cs
+				ON cs.namespace = c.namespace AND cs.collection_name = c.name
+			LEFT JOIN strings s
+				ON s.namespace = cs.namespace AND s.sha256_hash = cs.sha256_hash
+				AND s.deleted_at IS NULL AND (s.expires_at IS NULL OR s.expires_at > CURRENT_TIMESTAMP)
+		WHERE
+			c.namespace = @namespace
+		GROUP BY
+			c.name, c.created_at
+		ORDER BY
+			c.name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"namespace": namespace,
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List collections query failed!")
+		return nil, fmt.Errorf("failed to execute list collections query: %w", err)
+	}
+
+	collections, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Collection])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+func (r *StringRepository) CreateCollection(ctx context.Context, namespace string, name string) (*model.Collection, error) {
+	stmt := `
+		INSERT INTO collections (namespace, name)
+		VALUES (@namespace, @name)
+		RETURNING name, created_at, 0 AS string_count
+	`
+
+	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"namespace": namespace,
+		"name":      name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute create collection query: %w", err)
+	}
+
+	collection, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Collection])
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return nil, errs.ErrAlreadyExists
+		}
+
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Create collection query failed!")
+		return nil, fmt.Errorf("failed to collect row from table:collections: %w", err)
+	}
+
+	return &collection, nil
+}
+
+func (r *StringRepository) DeleteCollection(ctx context.Context, namespace string, name string) error {
+	stmt := `DELETE FROM collections WHERE namespace = @namespace AND name = @name`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"namespace": namespace,
+		"name":      name,
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Delete collection query failed!")
+		return fmt.Errorf("failed to execute delete collection query: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return errs.ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+func (r *StringRepository) AddToCollection(ctx context.Context, namespace string, name string, hash string) error {
+	return pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		var exists bool
+		stmt := `SELECT EXISTS (SELECT 1 FROM collections WHERE namespace = @namespace AND name = @name)`
+		err := tx.QueryRow(ctx, stmt, pgx.NamedArgs{
+			"namespace": namespace,
+			"name":      name,
+		}).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check collection existence: %w", err)
+		}
+		if !exists {
+			return errs.ErrCollectionNotFound
+		}
+
+		if err = stringExists(ctx, tx, namespace, hash); err != nil {
+			return err
+		}
+
+		stmt = `
+			INSERT INTO collection_strings (namespace, collection_name, sha256_hash)
+			VALUES (@namespace, @name, @sha256_hash)
+			ON CONFLICT DO NOTHING
+		`
+
+		if _, err = tx.Exec(ctx, stmt, pgx.NamedArgs{
+			"namespace":   namespace,
+			"name":        name,
+			"sha256_hash": hash,
+		}); err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Add to collection query failed!")
+			return fmt.Errorf("failed to execute add to collection query: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *StringRepository) RemoveFromCollection(ctx context.Context, namespace string, name string, hash string) error {
+	stmt := `
+		DELETE FROM collection_strings
+		WHERE namespace = @namespace AND collection_name = @name AND sha256_hash = @sha256_hash
+	`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"namespace":   namespace,
+		"name":        name,
+		"sha256_hash": hash,
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Remove from collection query failed!")
+		return fmt.Errorf("failed to execute remove from collection query: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
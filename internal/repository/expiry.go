@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+// ReapExpired permanently removes up to batchSize expired strings across all
+// namespaces, writing each removal to the audit log. It returns the number of
+// strings removed; a result below batchSize means nothing is left to reap.
+func (r *StringRepository) ReapExpired(ctx context.Context, batchSize int) (int64, error) {
+	stmt := `
+		WITH expired AS (
+			SELECT namespace, sha256_hash
+			FROM strings
+			WHERE expires_at <= CURRENT_TIMESTAMP
+			ORDER BY expires_at
+			LIMIT @batch_size
+			FOR UPDATE SKIP LOCKED
+		), reaped AS (
+			DELETE FROM strings
+			USING expired
+			WHERE strings.namespace = expired.namespace AND strings.sha256_hash = expired.sha256_hash
+			RETURNING strings.namespace, strings.sha256_hash, ` + snapshotColumn + ` AS before
+		)
+		INSERT INTO audit_events (namespace, actor, request_id, operation, sha256_hash, before)
+		SELECT namespace, @actor, @request_id, @operation, sha256_hash, before
+		FROM reaped
+	`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"batch_size": batchSize,
+		"actor":      requestctx.Actor(ctx),
+		"request_id": nullable(requestctx.RequestID(ctx)),
+		"operation":  model.AuditExpire,
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Reap expired query failed!")
+		return 0, fmt.Errorf("failed to execute reap expired query: %w", err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
@@ -0,0 +1,865 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+)
+
+// memoryKey identifies a string within the in-memory store.
+type memoryKey struct {
+	namespace string
+	hash      string
+}
+
+// memoryAPIKey is an API key together with the hash it is looked up by.
+type memoryAPIKey struct {
+	model.APIKey
+	hash string
+}
+
+type memoryCollection struct {
+	createdAt time.Time
+	members   map[string]struct{}
+}
+
+// MemoryStore keeps every string, namespace and audit event in process
+// memory. It implements the same semantics as the Postgres repositories,
+// except that full-text search matches whole words without stemming. Data is
+// lost when the process exits.
+type MemoryStore struct {
+	logger *zerolog.Logger
+
+	mu          sync.RWMutex
+	namespaces  map[string]time.Time
+	strings     map[memoryKey]*model.String
+	tags        map[memoryKey]map[string]struct{}
+	collections map[string]map[string]*memoryCollection
+	audit       []model.AuditEvent
+	apiKeys     []*memoryAPIKey
+}
+
+func NewMemoryStore(logger *zerolog.Logger) *MemoryStore {
+	return &MemoryStore{
+		logger:      logger,
+		namespaces:  map[string]time.Time{DefaultNamespace: time.Now()},
+		strings:     map[memoryKey]*model.String{},
+		tags:        map[memoryKey]map[string]struct{}{},
+		collections: map[string]map[string]*memoryCollection{},
+	}
+}
+
+// isLive mirrors the liveString SQL condition.
+func isLive(s *model.String, now time.Time) bool {
+	return s.DeletedAt == nil && !isExpired(s, now)
+}
+
+// isTrashed mirrors the trashedString SQL condition.
+func isTrashed(s *model.String, now time.Time) bool {
+	return s.DeletedAt != nil && !isExpired(s, now)
+}
+
+func isExpired(s *model.String, now time.Time) bool {
+	return s.ExpiresAt != nil && !s.ExpiresAt.After(now)
+}
+
+// view returns a copy of a stored string with its tags and collections
+// filled in, safe to hand out after the lock is released.
+func (m *MemoryStore) view(key memoryKey) model.String {
+	return m.selectedView(key, SelectAll)
+}
+
+// selectedView is view with only the parts selection asks for filled in.
+func (m *MemoryStore) selectedView(key memoryKey, selection Selection) model.String {
+	record := *m.strings[key]
+	record.Metadata, _ = cloneJSON(record.Metadata).(map[string]any)
+
+	if selection.Tags {
+		record.Tags = []string{}
+		for tag := range m.tags[key] {
+			record.Tags = append(record.Tags, tag)
+		}
+		sort.Strings(record.Tags)
+	}
+
+	if selection.Collections {
+		record.Collections = []string{}
+		for name, collection := range m.collections[key.namespace] {
+			if _, ok := collection.members[key.hash]; ok {
+				record.Collections = append(record.Collections, name)
+			}
+		}
+		sort.Strings(record.Collections)
+	}
+
+	return record
+}
+
+// summarize computes the ResultSummary of records the way the SQL stores
+// do, by summing a hash of each row.
+func summarize[T any](records []T) ResultSummary {
+	summary := ResultSummary{Count: len(records)}
+	for i := range records {
+		h := fnv.New32a()
+		// Records are built from JSON and the analyzer, so they encode.
+		_ = json.NewEncoder(h).Encode(&records[i])
+		summary.Digest += int64(h.Sum32())
+	}
+	return summary
+}
+
+// streamResults hands summary and then each of records to the callbacks of
+// a streamed query.
+func streamResults[T any](summary ResultSummary, records []T, start func(ResultSummary) error, row func(*T) error) error {
+	if err := start(summary); err != nil {
+		return err
+	}
+	for i := range records {
+		if err := row(&records[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check runs precondition, if any, against the string at key.
+func (m *MemoryStore) check(key memoryKey, precondition Precondition) error {
+	if precondition == nil {
+		return nil
+	}
+	record := m.view(key)
+	return precondition(&record)
+}
+
+// snapshot renders a stored string the way the Postgres audit log does: the
+// table columns, without tags, collections or the search vector.
+func (m *MemoryStore) snapshot(key memoryKey) []byte {
+	s := m.strings[key]
+	snapshot, _ := json.Marshal(map[string]any{
+		"namespace":         s.Namespace,
+		"created_at":        s.CreatedAt,
+		"string_value":      s.StringValue,
+		"is_palindrome":     s.IsPalindrome,
+		"unique_characters": s.UniqueCharacters,
+		"word_count":        s.WordCount,
+		"sha256_hash":       s.Hash,
+		"length":            s.Length,
+		"metadata":          s.Metadata,
+		"deleted_at":        s.DeletedAt,
+		"expires_at":        s.ExpiresAt,
+	})
+	return snapshot
+}
+
+// record appends an audit event. Callers must hold the write lock.
+func (m *MemoryStore) record(ctx context.Context, entry auditEntry) {
+	event := model.AuditEvent{
+		ID:         int64(len(m.audit)) + 1,
+		OccurredAt: time.Now(),
+		Namespace:  entry.Namespace,
+		Actor:      requestctx.Actor(ctx),
+		Operation:  entry.Operation,
+		Before:     entry.Before,
+		After:      entry.After,
+	}
+	if id := requestctx.RequestID(ctx); id != "" {
+		event.RequestID = &id
+	}
+	if entry.Hash != "" {
+		hash := entry.Hash
+		event.StringID = &hash
+	}
+
+	m.audit = append(m.audit, event)
+}
+
+// remove drops a string together with its tags and collection memberships.
+// Callers must hold the write lock.
+func (m *MemoryStore) remove(key memoryKey) {
+	delete(m.strings, key)
+	delete(m.tags, key)
+	for _, collection := range m.collections[key.namespace] {
+		delete(collection.members, key.hash)
+	}
+}
+
+// The listing queries copy their results out under the lock and stream them
+// after releasing it, so that a slow client cannot hold up writers.
+
+func (m *MemoryStore) GetFilteredStrings(ctx context.Context, namespace string, params dto.QueryParams, selection Selection, start func(ResultSummary) error, row func(*model.StringMatch) error) error {
+	records := m.filteredStrings(namespace, params, selection)
+	return streamResults(summarize(records), records, start, row)
+}
+
+func (m *MemoryStore) filteredStrings(namespace string, params dto.QueryParams, selection Selection) []model.StringMatch {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	search := parseSearchQuery(params.Query)
+	records := []model.StringMatch{}
+
+	for key, s := range m.strings {
+		if key.namespace != namespace || !isLive(s, now) {
+			continue
+		}
+		if !matchesFilters(s, params.IsPalindrome, params.MinLength, params.MaxLength, params.WordCount, params.ContainsCharacter) {
+			continue
+		}
+		if !m.hasTags(key, params.Tags) {
+			continue
+		}
+		if params.Collection != "" && !m.inCollection(key, params.Collection) {
+			continue
+		}
+		if len(params.Metadata) > 0 && !util.JSONContains(s.Metadata, params.Metadata) {
+			continue
+		}
+		if !hasKeys(s.Metadata, params.MetadataKeys) {
+			continue
+		}
+
+		match := model.StringMatch{String: m.selectedView(key, selection)}
+		if params.Query != "" {
+			rank, snippet, ok := search.match(s.StringValue, selection.Snippet)
+			if !ok {
+				continue
+			}
+			match.Rank, match.Snippet = rank, snippet
+		}
+
+		records = append(records, match)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Rank != records[j].Rank {
+			return records[i].Rank > records[j].Rank
+		}
+		return records[i].CreatedAt.Before(records[j].CreatedAt)
+	})
+
+	return records
+}
+
+func (m *MemoryStore) GetStringByValue(ctx context.Context, namespace string, value string) (*model.String, error) {
+	return m.GetStringByHash(ctx, namespace, util.Hash(value))
+}
+
+func (m *MemoryStore) GetStringByHash(ctx context.Context, namespace string, hash string) (*model.String, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key := memoryKey{namespace, hash}
+	s, ok := m.strings[key]
+	if !ok || !isLive(s, time.Now()) {
+		return nil, nil
+	}
+
+	record := m.view(key)
+	return &record, nil
+}
+
+func (m *MemoryStore) CreateString(ctx context.Context, namespace string, payload *dto.CreateString) (*model.String, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.namespaces[namespace]; !ok {
+		return nil, errs.ErrNamespaceNotFound
+	}
+
+	now := time.Now()
+	key := memoryKey{namespace, payload.Hash}
+
+	if existing, ok := m.strings[key]; ok {
+		if isLive(existing, now) {
+			return nil, errs.ErrAlreadyExists
+		}
+
+		operation := model.AuditPurge
+		if isExpired(existing, now) {
+			operation = model.AuditExpire
+		}
+		m.record(ctx, auditEntry{Namespace: namespace, Operation: operation, Hash: payload.Hash, Before: m.snapshot(key)})
+		m.remove(key)
+	}
+
+	metadata := payload.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+
+	m.strings[key] = &model.String{
+		Namespace:        namespace,
+		CreatedAt:        now,
+		StringValue:      payload.StringValue,
+		IsPalindrome:     payload.IsPalindrome,
+		UniqueCharacters: payload.UniqueCharacters,
+		WordCount:        payload.WordCount,
+		Hash:             payload.Hash,
+		Length:           payload.Length,
+		Metadata:         cloneJSON(metadata).(map[string]any),
+		ExpiresAt:        payload.ExpiresAt,
+	}
+	m.record(ctx, auditEntry{Namespace: namespace, Operation: model.AuditCreate, Hash: payload.Hash, After: m.snapshot(key)})
+
+	record := m.view(key)
+	return &record, nil
+}
+
+func (m *MemoryStore) PatchMetadata(ctx context.Context, namespace string, hash string, patch any, precondition Precondition) (*model.String, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memoryKey{namespace, hash}
+	s, ok := m.strings[key]
+	if !ok || !isLive(s, time.Now()) {
+		return nil, errs.ErrNotFound
+	}
+
+	if err := m.check(key, precondition); err != nil {
+		return nil, err
+	}
+
+	before := m.snapshot(key)
+
+	metadata, ok := util.MergePatch(s.Metadata, cloneJSON(patch)).(map[string]any)
+	if !ok {
+		metadata = map[string]any{}
+	}
+	s.Metadata = metadata
+
+	m.record(ctx, auditEntry{Namespace: namespace, Operation: model.AuditUpdate, Hash: hash, Before: before, After: m.snapshot(key)})
+
+	record := m.view(key)
+	return &record, nil
+}
+
+func (m *MemoryStore) DeleteString(ctx context.Context, namespace string, value string, hard bool, precondition Precondition) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	key := memoryKey{namespace, util.Hash(value)}
+	s, ok := m.strings[key]
+	if !ok || isExpired(s, now) || (!hard && s.DeletedAt != nil) {
+		return errs.ErrNotFound
+	}
+
+	if err := m.check(key, precondition); err != nil {
+		return err
+	}
+
+	before := m.snapshot(key)
+
+	if hard {
+		m.remove(key)
+		m.record(ctx, auditEntry{Namespace: namespace, Operation: model.AuditPurge, Hash: key.hash, Before: before})
+		return nil
+	}
+
+	s.DeletedAt = &now
+	m.record(ctx, auditEntry{Namespace: namespace, Operation: model.AuditDelete, Hash: key.hash, Before: before, After: m.snapshot(key)})
+	return nil
+}
+
+func (m *MemoryStore) GetFilteredStringsByNaturalLanguage(ctx context.Context, namespace string, params *dto.FilterParams, selection Selection, start func(ResultSummary) error, row func(*model.String) error) error {
+	records := m.naturalLanguageStrings(namespace, params, selection)
+	return streamResults(summarize(records), records, start, row)
+}
+
+func (m *MemoryStore) naturalLanguageStrings(namespace string, params *dto.FilterParams, selection Selection) []model.String {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	containsCharacter := ""
+	if params.ContainsCharacter != nil {
+		containsCharacter = *params.ContainsCharacter
+	}
+
+	now := time.Now()
+	records := []model.String{}
+	for key, s := range m.strings {
+		if key.namespace != namespace || !isLive(s, now) {
+			continue
+		}
+		if !matchesFilters(s, params.IsPalindrome, params.MinLength, params.MaxLength, params.WordCount, containsCharacter) {
+			continue
+		}
+		records = append(records, m.selectedView(key, selection))
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+
+	return records
+}
+
+func (m *MemoryStore) AddTags(ctx context.Context, namespace string, hash string, tags []string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memoryKey{namespace, hash}
+	if s, ok := m.strings[key]; !ok || !isLive(s, time.Now()) {
+		return nil, errs.ErrNotFound
+	}
+
+	if m.tags[key] == nil {
+		m.tags[key] = map[string]struct{}{}
+	}
+	for _, tag := range tags {
+		m.tags[key][tag] = struct{}{}
+	}
+
+	return m.view(key).Tags, nil
+}
+
+func (m *MemoryStore) RemoveTag(ctx context.Context, namespace string, hash string, tag string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memoryKey{namespace, hash}
+	if _, ok := m.tags[key][tag]; !ok {
+		return errs.ErrNotFound
+	}
+
+	delete(m.tags[key], tag)
+	return nil
+}
+
+func (m *MemoryStore) ListCollections(ctx context.Context, namespace string) ([]model.Collection, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	collections := []model.Collection{}
+	for name, collection := range m.collections[namespace] {
+		count := 0
+		for hash := range collection.members {
+			if s, ok := m.strings[memoryKey{namespace, hash}]; ok && isLive(s, now) {
+				count++
+			}
+		}
+
+		collections = append(collections, model.Collection{
+			Name:        name,
+			CreatedAt:   collection.createdAt,
+			StringCount: count,
+		})
+	}
+
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Name < collections[j].Name
+	})
+
+	return collections, nil
+}
+
+func (m *MemoryStore) CreateCollection(ctx context.Context, namespace string, name string) (*model.Collection, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.collections[namespace][name]; ok {
+		return nil, errs.ErrAlreadyExists
+	}
+
+	if m.collections[namespace] == nil {
+		m.collections[namespace] = map[string]*memoryCollection{}
+	}
+
+	collection := &memoryCollection{createdAt: time.Now(), members: map[string]struct{}{}}
+	m.collections[namespace][name] = collection
+
+	return &model.Collection{Name: name, CreatedAt: collection.createdAt}, nil
+}
+
+func (m *MemoryStore) DeleteCollection(ctx context.Context, namespace string, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.collections[namespace][name]; !ok {
+		return errs.ErrCollectionNotFound
+	}
+
+	delete(m.collections[namespace], name)
+	return nil
+}
+
+func (m *MemoryStore) AddToCollection(ctx context.Context, namespace string, name string, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	collection, ok := m.collections[namespace][name]
+	if !ok {
+		return errs.ErrCollectionNotFound
+	}
+
+	if s, ok := m.strings[memoryKey{namespace, hash}]; !ok || !isLive(s, time.Now()) {
+		return errs.ErrNotFound
+	}
+
+	collection.members[hash] = struct{}{}
+	return nil
+}
+
+func (m *MemoryStore) RemoveFromCollection(ctx context.Context, namespace string, name string, hash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	collection, ok := m.collections[namespace][name]
+	if !ok {
+		return errs.ErrNotFound
+	}
+	if _, ok := collection.members[hash]; !ok {
+		return errs.ErrNotFound
+	}
+
+	delete(collection.members, hash)
+	return nil
+}
+
+func (m *MemoryStore) ListTrash(ctx context.Context, namespace string) ([]model.String, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	records := []model.String{}
+	for key, s := range m.strings {
+		if key.namespace == namespace && isTrashed(s, now) {
+			records = append(records, m.view(key))
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].DeletedAt.After(*records[j].DeletedAt)
+	})
+
+	return records, nil
+}
+
+func (m *MemoryStore) RestoreString(ctx context.Context, namespace string, hash string) (*model.String, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memoryKey{namespace, hash}
+	s, ok := m.strings[key]
+	if !ok || !isTrashed(s, time.Now()) {
+		return nil, errs.ErrNotFound
+	}
+
+	before := m.snapshot(key)
+	s.DeletedAt = nil
+	m.record(ctx, auditEntry{Namespace: namespace, Operation: model.AuditRestore, Hash: hash, Before: before, After: m.snapshot(key)})
+
+	record := m.view(key)
+	return &record, nil
+}
+
+func (m *MemoryStore) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var removed int64
+	for key, s := range m.strings {
+		if s.DeletedAt == nil || !s.DeletedAt.Before(cutoff) {
+			continue
+		}
+
+		m.record(ctx, auditEntry{Namespace: key.namespace, Operation: model.AuditPurge, Hash: key.hash, Before: m.snapshot(key)})
+		m.remove(key)
+		removed++
+	}
+
+	return removed, nil
+}
+
+func (m *MemoryStore) ReapExpired(ctx context.Context, batchSize int) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var removed int64
+	for key, s := range m.strings {
+		if removed >= int64(batchSize) {
+			break
+		}
+		if !isExpired(s, now) {
+			continue
+		}
+
+		m.record(ctx, auditEntry{Namespace: key.namespace, Operation: model.AuditExpire, Hash: key.hash, Before: m.snapshot(key)})
+		m.remove(key)
+		removed++
+	}
+
+	return removed, nil
+}
+
+func (m *MemoryStore) ListNamespaces(ctx context.Context) ([]model.Namespace, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	counts := map[string]int{}
+	for key, s := range m.strings {
+		if isLive(s, now) {
+			counts[key.namespace]++
+		}
+	}
+
+	namespaces := []model.Namespace{}
+	for name, createdAt := range m.namespaces {
+		namespaces = append(namespaces, model.Namespace{Name: name, CreatedAt: createdAt, StringCount: counts[name]})
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Name < namespaces[j].Name
+	})
+
+	return namespaces, nil
+}
+
+func (m *MemoryStore) NamespaceExists(ctx context.Context, name string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, ok := m.namespaces[name]
+	return ok, nil
+}
+
+func (m *MemoryStore) CreateNamespace(ctx context.Context, name string) (*model.Namespace, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.namespaces[name]; ok {
+		return nil, errs.ErrAlreadyExists
+	}
+
+	createdAt := time.Now()
+	m.namespaces[name] = createdAt
+
+	return &model.Namespace{Name: name, CreatedAt: createdAt}, nil
+}
+
+func (m *MemoryStore) DeleteNamespace(ctx context.Context, name string) error {
+	if name == DefaultNamespace {
+		return errs.ErrDefaultNamespace
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.namespaces[name]; !ok {
+		return errs.ErrNamespaceNotFound
+	}
+
+	for key := range m.strings {
+		if key.namespace == name {
+			m.remove(key)
+		}
+	}
+	delete(m.collections, name)
+	delete(m.namespaces, name)
+
+	m.record(ctx, auditEntry{Namespace: name, Operation: model.AuditDeleteNamespace})
+	return nil
+}
+
+func (m *MemoryStore) ListAuditEvents(ctx context.Context, params dto.AuditQuery) ([]model.AuditEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	events := []model.AuditEvent{}
+	for i := len(m.audit) - 1; i >= 0 && len(events) < params.Limit; i-- {
+		event := m.audit[i]
+
+		switch {
+		case params.Cursor != nil && event.ID >= *params.Cursor,
+			params.Namespace != "" && event.Namespace != params.Namespace,
+			params.Actor != "" && event.Actor != params.Actor,
+			params.Operation != "" && event.Operation != params.Operation,
+			params.StringID != "" && (event.StringID == nil || *event.StringID != params.StringID),
+			params.RequestID != "" && (event.RequestID == nil || *event.RequestID != params.RequestID),
+			params.Since != nil && event.OccurredAt.Before(*params.Since),
+			params.Until != nil && !event.OccurredAt.Before(*params.Until):
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (m *MemoryStore) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []model.APIKey{}
+	for _, key := range m.apiKeys {
+		keys = append(keys, key.view())
+	}
+
+	return keys, nil
+}
+
+func (m *MemoryStore) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, key := range m.apiKeys {
+		if key.hash == hash && key.RevokedAt == nil {
+			view := key.view()
+			return &view, nil
+		}
+	}
+
+	return nil, errs.ErrAPIKeyNotFound
+}
+
+func (m *MemoryStore) CreateAPIKey(ctx context.Context, payload *dto.StoreAPIKey) (*model.APIKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := &memoryAPIKey{
+		APIKey: model.APIKey{
+			ID:        int64(len(m.apiKeys) + 1),
+			Name:      payload.Name,
+			Prefix:    payload.Prefix,
+			Scopes:    append([]string(nil), payload.Scopes...),
+			CreatedAt: time.Now().UTC(),
+		},
+		hash: payload.Hash,
+	}
+	m.apiKeys = append(m.apiKeys, key)
+
+	view := key.view()
+	return &view, nil
+}
+
+func (m *MemoryStore) RevokeAPIKey(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id < 1 || id > int64(len(m.apiKeys)) || m.apiKeys[id-1].RevokedAt != nil {
+		return errs.ErrAPIKeyNotFound
+	}
+
+	now := time.Now().UTC()
+	m.apiKeys[id-1].RevokedAt = &now
+	return nil
+}
+
+// view returns a copy of the key safe to hand out after the lock is released.
+func (k *memoryAPIKey) view() model.APIKey {
+	key := k.APIKey
+	key.Scopes = append([]string(nil), k.Scopes...)
+	return key
+}
+
+func (m *MemoryStore) hasTags(key memoryKey, tags []string) bool {
+	for _, tag := range tags {
+		if _, ok := m.tags[key][tag]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *MemoryStore) inCollection(key memoryKey, name string) bool {
+	collection, ok := m.collections[key.namespace][name]
+	if !ok {
+		return false
+	}
+	_, ok = collection.members[key.hash]
+	return ok
+}
+
+// matchesFilters applies the property filters shared by the structured and
+// natural language queries.
+func matchesFilters(s *model.String, isPalindrome *bool, minLength, maxLength, wordCount *int, containsCharacter string) bool {
+	switch {
+	case isPalindrome != nil && s.IsPalindrome != *isPalindrome,
+		minLength != nil && s.Length < *minLength,
+		maxLength != nil && s.Length > *maxLength,
+		wordCount != nil && s.WordCount != *wordCount,
+		containsCharacter != "" && !likeContains(s.StringValue, containsCharacter):
+		return false
+	}
+	return true
+}
+
+// likeContains mirrors `value ILIKE '%' || pattern || '%'`, including the
+// LIKE wildcards '%' and '_' a pattern may carry.
+func likeContains(value, pattern string) bool {
+	v := []rune(strings.ToLower(value))
+	p := []rune(strings.ToLower(pattern))
+
+	var match func(vi, pi int) bool
+	match = func(vi, pi int) bool {
+		if pi == len(p) {
+			return true
+		}
+		switch p[pi] {
+		case '%':
+			for i := vi; i <= len(v); i++ {
+				if match(i, pi+1) {
+					return true
+				}
+			}
+			return false
+		case '_':
+			return vi < len(v) && match(vi+1, pi+1)
+		default:
+			return vi < len(v) && v[vi] == p[pi] && match(vi+1, pi+1)
+		}
+	}
+
+	for start := 0; start <= len(v); start++ {
+		if match(start, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKeys mirrors the jsonb ?& operator.
+func hasKeys(metadata map[string]any, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := metadata[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// cloneJSON deep-copies a decoded JSON value.
+func cloneJSON(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for key, inner := range val {
+			out[key] = cloneJSON(inner)
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, inner := range val {
+			out[i] = cloneJSON(inner)
+		}
+		return out
+	default:
+		return v
+	}
+}
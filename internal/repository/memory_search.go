@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+// searchQuery is the in-memory counterpart of websearch_to_tsquery: bare
+// words and "quoted phrases" must all appear, and terms prefixed with '-'
+// must not. Matching is case-insensitive on whole words, without stemming.
+type searchQuery struct {
+	include [][]string
+	exclude [][]string
+}
+
+type searchToken struct {
+	word       string
+	start, end int
+}
+
+// tokenize splits s into lowercase words of letters and digits, keeping
+// their byte offsets so matches can be highlighted.
+func tokenize(s string) []searchToken {
+	var tokens []searchToken
+	start := -1
+
+	for i, r := range s {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			tokens = append(tokens, searchToken{strings.ToLower(s[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, searchToken{strings.ToLower(s[start:]), start, len(s)})
+	}
+
+	return tokens
+}
+
+func words(s string) []string {
+	var out []string
+	for _, token := range tokenize(s) {
+		out = append(out, token.word)
+	}
+	return out
+}
+
+func parseSearchQuery(query string) searchQuery {
+	var q searchQuery
+
+	for rest := strings.TrimSpace(query); rest != ""; rest = strings.TrimSpace(rest) {
+		negate := strings.HasPrefix(rest, "-")
+		if negate {
+			rest = rest[1:]
+		}
+
+		var term string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				term, rest = rest[1:], ""
+			} else {
+				term, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			term, rest = rest[:end], rest[end:]
+		}
+
+		phrase := words(term)
+		if len(phrase) == 0 {
+			continue
+		}
+		if negate {
+			q.exclude = append(q.exclude, phrase)
+		} else {
+			q.include = append(q.include, phrase)
+		}
+	}
+
+	return q
+}
+
+// match reports whether value satisfies the query and, if so, a rank based
+// on how densely the terms occur and, when highlight is set, a snippet with
+// matches wrapped in <mark> tags like the Postgres headline.
+func (q searchQuery) match(value string, highlight bool) (float32, string, bool) {
+	tokens := tokenize(value)
+	if len(q.include) == 0 && len(q.exclude) == 0 {
+		return 0, "", false
+	}
+
+	for _, phrase := range q.exclude {
+		if len(phraseOccurrences(tokens, phrase)) > 0 {
+			return 0, "", false
+		}
+	}
+
+	// A purely negative query matches everything it does not exclude.
+	if len(q.include) == 0 {
+		return 0, "", true
+	}
+	if len(tokens) == 0 {
+		return 0, "", false
+	}
+
+	marked := make([]bool, len(tokens))
+	hits := 0
+	for _, phrase := range q.include {
+		occurrences := phraseOccurrences(tokens, phrase)
+		if len(occurrences) == 0 {
+			return 0, "", false
+		}
+		for _, start := range occurrences {
+			for i := start; i < start+len(phrase); i++ {
+				marked[i] = true
+			}
+		}
+		hits += len(occurrences)
+	}
+	rank := float32(hits) / float32(len(tokens))
+	if !highlight {
+		return rank, "", true
+	}
+
+	var snippet strings.Builder
+	last := 0
+	for i, token := range tokens {
+		if !marked[i] {
+			continue
+		}
+		snippet.WriteString(value[last:token.start])
+		snippet.WriteString("<mark>")
+		snippet.WriteString(value[token.start:token.end])
+		snippet.WriteString("</mark>")
+		last = token.end
+	}
+	snippet.WriteString(value[last:])
+
+	return rank, snippet.String(), true
+}
+
+// phraseOccurrences returns the token indexes at which phrase starts.
+func phraseOccurrences(tokens []searchToken, phrase []string) []int {
+	var starts []int
+	for i := 0; i+len(phrase) <= len(tokens); i++ {
+		matched := true
+		for j, word := range phrase {
+			if tokens[i+j].word != word {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
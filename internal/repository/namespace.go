@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/justinndidit/stringAnalyzer/internal/database"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/rs/zerolog"
+)
+
+// DefaultNamespace holds every string uploaded without an explicit namespace.
+// It is created by the migrations and cannot be deleted.
+const DefaultNamespace = "default"
+
+type NamespaceRepository struct {
+	logger *zerolog.Logger
+	db     *database.Database
+}
+
+func NewNamespaceRepository(logger *zerolog.Logger, db *database.Database) *NamespaceRepository {
+	return &NamespaceRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (r *NamespaceRepository) ListNamespaces(ctx context.Context) ([]model.Namespace, error) {
+	stmt := `
+		SELECT
+			n.name,
+			n.created_at,
+			COUNT(s.sha256_hash)::int AS string_count
+		FROM
+			namespaces n
+			LEFT JOIN strings s ON s.namespace = n.name
+				AND s.deleted_at IS NULL AND (s.expires_at IS NULL OR s.expires_at > CURRENT_TIMESTAMP)
+		GROUP BY
+			n.name, n.created_at
+		ORDER BY
+			n.name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, stmt)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List namespaces query failed!")
+		return nil, fmt.Errorf("failed to execute list namespaces query: %w", err)
+	}
+
+	namespaces, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Namespace])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:namespaces: %w", err)
+	}
+
+	return namespaces, nil
+}
+
+func (r *NamespaceRepository) NamespaceExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+
+	err := r.db.Pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM namespaces WHERE name = @name)`, pgx.NamedArgs{
+		"name": name,
+	}).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check namespace existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *NamespaceRepository) CreateNamespace(ctx context.Context, name string) (*model.Namespace, error) {
+	stmt := `
+		INSERT INTO namespaces (name)
+		VALUES (@name)
+		RETURNING name, created_at, 0 AS string_count
+	`
+
+	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"name": name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute create namespace query: %w", err)
+	}
+
+	namespace, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Namespace])
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return nil, errs.ErrAlreadyExists
+		}
+
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Create namespace query failed!")
+		return nil, fmt.Errorf("failed to collect row from table:namespaces: %w", err)
+	}
+
+	return &namespace, nil
+}
+
+// DeleteNamespace removes a namespace together with every string, tag and
+// collection stored in it. The audit log keeps a single event for the
+// namespace rather than one per string.
+func (r *NamespaceRepository) DeleteNamespace(ctx context.Context, name string) error {
+	if name == DefaultNamespace {
+		return errs.ErrDefaultNamespace
+	}
+
+	return pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		cmdTag, err := tx.Exec(ctx, `DELETE FROM namespaces WHERE name = @name`, pgx.NamedArgs{
+			"name": name,
+		})
+		if err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Delete namespace query failed!")
+			return fmt.Errorf("failed to execute delete namespace query: %w", err)
+		}
+
+		if cmdTag.RowsAffected() == 0 {
+			return errs.ErrNamespaceNotFound
+		}
+
+		return recordAudit(ctx, tx, auditEntry{
+			Namespace: name,
+			Operation: model.AuditDeleteNamespace,
+		})
+	})
+}
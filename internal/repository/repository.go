@@ -6,41 +6,189 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/justinndidit/stringAnalyzer/internal/config"
 	"github.com/justinndidit/stringAnalyzer/internal/database"
 	"github.com/justinndidit/stringAnalyzer/internal/dto"
 	"github.com/justinndidit/stringAnalyzer/internal/errs"
 
 	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
 	"github.com/rs/zerolog"
 )
 
+// stringColumns lists the columns that map onto model.String. The table also
+// carries columns (such as search_vector) that are never read back, and the
+// tags and collections are aggregated from their link tables.
+const stringColumns = ownStringColumns + `,` + tagsColumn + `,` + collectionsColumn
+
+const ownStringColumns = `
+	namespace,
+	created_at,
+	string_value,
+	is_palindrome,
+	unique_characters,
+	word_count,
+	sha256_hash,
+	length,
+	metadata,
+	deleted_at,
+	expires_at
+`
+
+const tagsColumn = `
+	ARRAY(
+		SELECT t.tag FROM string_tags t
+		WHERE t.namespace = strings.namespace AND t.sha256_hash = strings.sha256_hash
+		ORDER BY t.tag
+	) AS tags
+`
+
+const collectionsColumn = `
+	ARRAY(
+		SELECT c.collection_name FROM collection_strings c
+		WHERE c.namespace = strings.namespace AND c.sha256_hash = strings.sha256_hash
+		ORDER BY c.collection_name
+	) AS collections
+`
+
+// selectedStringColumns is stringColumns with the link tables only queried
+// for the parts selection asks for.
+func selectedStringColumns(selection Selection) string {
+	tags, collections := `NULL::text[] AS tags`, `NULL::text[] AS collections`
+	if selection.Tags {
+		tags = tagsColumn
+	}
+	if selection.Collections {
+		collections = collectionsColumn
+	}
+	return ownStringColumns + `, ` + tags + `, ` + collections
+}
+
+// summarizedQuery wraps stmt so that each of its rows also carries the
+// ResultSummary of the whole result, computed by window functions. The
+// digest sums a hash of the text of every row.
+func summarizedQuery(stmt string, orderBy string) string {
+	return `
+		SELECT
+			results.*,
+			COUNT(*) OVER () AS result_count,
+			SUM(hashtext(results::text)) OVER () AS result_digest
+		FROM (` + stmt + `) results
+		ORDER BY
+			` + orderBy
+}
+
+// streamSummarized reads the rows of a summarizedQuery into T. It calls
+// start with the summary the first row carries, or an empty one when there
+// are no rows, then row with each of them.
+func streamSummarized[T any](rows pgx.Rows, summary func(*T) ResultSummary, start func(ResultSummary) error, row func(*T) error) error {
+	defer rows.Close()
+
+	started := false
+	for rows.Next() {
+		record, err := pgx.RowToStructByName[T](rows)
+		if err != nil {
+			return fmt.Errorf("failed to collect row from table:strings: %w", err)
+		}
+		if !started {
+			started = true
+			if err := start(summary(&record)); err != nil {
+				return err
+			}
+		}
+		if err := row(&record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to collect row from table:strings: %w", err)
+	}
+
+	if !started {
+		return start(ResultSummary{})
+	}
+	return nil
+}
+
+// Row conditions for strings visible to readers and for strings in the trash.
+// Expired strings are hidden from both, even before the reaper removes them.
+const (
+	notExpired    = `(expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`
+	liveString    = `deleted_at IS NULL AND ` + notExpired
+	trashedString = `deleted_at IS NOT NULL AND ` + notExpired
+)
+
+// headlineOptions controls how ts_headline marks matched terms in snippets.
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+
 type StringRepository struct {
-	logger *zerolog.Logger
-	db     *database.Database
+	logger         *zerolog.Logger
+	db             *database.Database
+	searchLanguage string
 }
 
-func NewStringRepository(logger *zerolog.Logger, db *database.Database) *StringRepository {
+func NewStringRepository(logger *zerolog.Logger, db *database.Database, cfg *config.Config) *StringRepository {
 	return &StringRepository{
-		logger: logger,
-		db:     db,
+		logger:         logger,
+		db:             db,
+		searchLanguage: cfg.Search.Language,
 	}
 }
 
-func (r *StringRepository) GetFilteredStrings(ctx context.Context, params dto.QueryParams) ([]model.String, error) {
+func (r *StringRepository) GetFilteredStrings(ctx context.Context, namespace string, params dto.QueryParams, selection Selection, start func(ResultSummary) error, row func(*model.StringMatch) error) error {
+	// The query is parsed once by the lateral join. Ranking orders the
+	// results so it always runs, but headlines are only built when asked
+	// for.
+	snippet := `''`
+	if selection.Snippet {
+		snippet = `COALESCE(ts_headline(@language::regconfig, string_value, search.query, @headline_options), '')`
+	}
+
 	stmt := `
 		SELECT
-			*
+			` + selectedStringColumns(selection) + `,
+			COALESCE(ts_rank_cd(search_vector, search.query), 0) AS rank,
+			` + snippet + ` AS snippet
 		FROM
 			strings
+			LEFT JOIN LATERAL (
+				SELECT websearch_to_tsquery(@language::regconfig, @query::text) AS query
+			) search ON TRUE
 		WHERE
-			(@is_palindrome::boolean IS NULL OR is_palindrome = @is_palindrome::boolean)
+			namespace = @namespace
+			AND ` + liveString + `
+			AND (@query::text IS NULL OR search_vector @@ search.query)
+			AND (@is_palindrome::boolean IS NULL OR is_palindrome = @is_palindrome::boolean)
 			AND (@min_length::int IS NULL OR length >= @min_length::int)
 			AND (@max_length::int IS NULL OR length <= @max_length::int)
 			AND (@word_count::int IS NULL OR word_count = @word_count::int)
-			AND (@contains_character::text IS NULL OR string_value ILIKE '%' || @contains_character || '%');
+			AND (@contains_character::text IS NULL OR string_value ILIKE '%' || @contains_character || '%')
+			AND (@tags::text[] IS NULL OR @tags::text[] <@ ARRAY(
+				SELECT t.tag FROM string_tags t
+				WHERE t.namespace = strings.namespace AND t.sha256_hash = strings.sha256_hash
+			))
+			AND (@metadata::jsonb IS NULL OR metadata @> @metadata::jsonb)
+			AND (@metadata_keys::text[] IS NULL OR metadata ?& @metadata_keys::text[])
+			AND (@collection::text IS NULL OR EXISTS (
+				SELECT 1 FROM collection_strings c
+				WHERE c.namespace = strings.namespace
+					AND c.collection_name = @collection::text
+					AND c.sha256_hash = strings.sha256_hash
+			))
 	`
 
-	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+	rows, err := r.db.Pool.Query(ctx, summarizedQuery(stmt, "rank DESC"), pgx.NamedArgs{
+		"namespace":        namespace,
+		"language":         r.searchLanguage,
+		"headline_options": headlineOptions,
+		"query": func() any {
+			if params.Query == "" {
+				return nil
+			}
+			return params.Query
+		}(),
 		"is_palindrome": func() any {
 			if params.IsPalindrome == nil {
 				return nil
@@ -66,33 +214,67 @@ func (r *StringRepository) GetFilteredStrings(ctx context.Context, params dto.Qu
 			return *params.WordCount
 		}(),
 		"contains_character": params.ContainsCharacter,
+		"tags": func() any {
+			if len(params.Tags) == 0 {
+				return nil
+			}
+			return params.Tags
+		}(),
+		"collection": func() any {
+			if params.Collection == "" {
+				return nil
+			}
+			return params.Collection
+		}(),
+		"metadata": func() any {
+			if len(params.Metadata) == 0 {
+				return nil
+			}
+			return params.Metadata
+		}(),
+		"metadata_keys": func() any {
+			if len(params.MetadataKeys) == 0 {
+				return nil
+			}
+			return params.MetadataKeys
+		}(),
 	})
 
 	if err != nil {
-		r.logger.Error().Err(err).Msg("Query Failed!")
-		return nil, fmt.Errorf("failed to execute string query: %w", err)
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Query Failed!")
+		return fmt.Errorf("failed to execute string query: %w", err)
 	}
 
-	records, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.String])
-	if err != nil {
-		return nil, fmt.Errorf("failed to collect row from table:strings: %w", err)
+	type summarizedMatch struct {
+		model.StringMatch
+		ResultSummary
 	}
+	return streamSummarized(rows,
+		func(match *summarizedMatch) ResultSummary { return match.ResultSummary },
+		start,
+		func(match *summarizedMatch) error { return row(&match.StringMatch) },
+	)
+}
 
-	return records, nil
+func (r *StringRepository) GetStringByValue(ctx context.Context, namespace string, value string) (*model.String, error) {
+	return r.GetStringByHash(ctx, namespace, util.Hash(value))
 }
 
-func (r *StringRepository) GetStringByValue(ctx context.Context, value string) (*model.String, error) {
+func (r *StringRepository) GetStringByHash(ctx context.Context, namespace string, hash string) (*model.String, error) {
 	stmt := `
 		SELECT
-			*
+			` + stringColumns + `
 		FROM
 			strings
 		WHERE
-			string_value = @value
+			namespace = @namespace
+			AND sha256_hash = @sha256_hash
+			AND ` + liveString + `
 	`
 
 	args := pgx.NamedArgs{
-		"value": value,
+		"namespace":   namespace,
+		"sha256_hash": hash,
 	}
 
 	rows, err := r.db.Pool.Query(ctx, stmt, args)
@@ -104,96 +286,331 @@ func (r *StringRepository) GetStringByValue(ctx context.Context, value string) (
 	record, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.String])
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			r.logger.Info().Msg("No rows matching query")
+			requestctx.Logger(ctx, r.logger).Info().Msg("No rows matching query")
 			return nil, nil // no match found, return nil without error
 		}
 
-		r.logger.Error().Err(err).Msg("No")
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("No")
 		return nil, fmt.Errorf("failed to collect row: %w", err)
 	}
 
 	return &record, nil
 }
 
-func (r *StringRepository) CreateString(ctx context.Context, payload *dto.CreateString) (*model.String, error) {
+// CreateString stores a new string. Uploading a value that sits in the trash,
+// or that has expired but not been reaped yet, replaces the old copy along
+// with its tags and collection memberships.
+func (r *StringRepository) CreateString(ctx context.Context, namespace string, payload *dto.CreateString) (*model.String, error) {
+	var newString model.String
+
+	err := pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		stmt := `
+			DELETE FROM strings
+			WHERE namespace = @namespace AND sha256_hash = @sha256_hash
+				AND NOT (` + liveString + `)
+			RETURNING ` + snapshotColumn + `, NOT ` + notExpired
+
+		var (
+			replaced []byte
+			expired  bool
+		)
+		err := tx.QueryRow(ctx, stmt, pgx.NamedArgs{
+			"namespace":   namespace,
+			"sha256_hash": payload.Hash,
+		}).Scan(&replaced, &expired)
+		switch {
+		case err == nil:
+			operation := model.AuditPurge
+			if expired {
+				operation = model.AuditExpire
+			}
+			if err = recordAudit(ctx, tx, auditEntry{
+				Namespace: namespace,
+				Operation: operation,
+				Hash:      payload.Hash,
+				Before:    replaced,
+			}); err != nil {
+				return err
+			}
+		case !errors.Is(err, pgx.ErrNoRows):
+			return fmt.Errorf("failed to clear trashed string: %w", err)
+		}
+
+		created, err := r.insertString(ctx, tx, namespace, payload)
+		if err != nil {
+			return err
+		}
+
+		after, err := snapshotString(ctx, tx, namespace, payload.Hash, "TRUE")
+		if err != nil {
+			return err
+		}
+
+		if err = recordAudit(ctx, tx, auditEntry{
+			Namespace: namespace,
+			Operation: model.AuditCreate,
+			Hash:      payload.Hash,
+			After:     after,
+		}); err != nil {
+			return err
+		}
+
+		newString = *created
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newString, nil
+}
 
+func (r *StringRepository) insertString(ctx context.Context, tx pgx.Tx, namespace string, payload *dto.CreateString) (*model.String, error) {
 	stmt := `
 		INSERT INTO strings (
+			namespace,
 			string_value,
 			is_palindrome,
 			unique_characters,
 			word_count,
 			sha256_hash,
-			length
+			length,
+			metadata,
+			expires_at,
+			search_vector
 		)
 		VALUES (
+			@namespace,
 			@string_value,
 			@is_palindrome,
 			@unique_characters,
 			@word_count,
 			@sha256_hash,
-			@length
+			@length,
+			@metadata,
+			@expires_at,
+			to_tsvector(@language::regconfig, @string_value)
 		)
-		RETURNING *
+		RETURNING ` + stringColumns + `
 	`
 
-	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+	rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+		"namespace":         namespace,
+		"language":          r.searchLanguage,
 		"string_value":      payload.StringValue,
 		"is_palindrome":     payload.IsPalindrome,
 		"unique_characters": payload.UniqueCharacters,
 		"word_count":        payload.WordCount,
 		"sha256_hash":       payload.Hash,
 		"length":            payload.Length,
+		"metadata": func() map[string]any {
+			if payload.Metadata == nil {
+				return map[string]any{}
+			}
+			return payload.Metadata
+		}(),
+		"expires_at": payload.ExpiresAt,
 	})
 	if err != nil {
-		r.logger.Error().Err(err).Msg("Query Failed!")
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Query Failed!")
 		return nil, fmt.Errorf("failed to execute create string query: %w", err)
 	}
 
 	newString, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.String])
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case foreignKeyViolation:
+				return nil, errs.ErrNamespaceNotFound
+			case uniqueViolation:
+				return nil, errs.ErrAlreadyExists
+			}
+		}
 		return nil, fmt.Errorf("failed to collect row from table:strings: %w", err)
 	}
 
 	return &newString, nil
 }
 
-func (r *StringRepository) DeleteString(ctx context.Context, value string) error {
-	stmt := `DELETE FROM strings WHERE string_value = @string_value`
+// PatchMetadata applies a JSON Merge Patch to the metadata of a stored string
+// and returns the updated record.
+func (r *StringRepository) PatchMetadata(ctx context.Context, namespace string, hash string, patch any, precondition Precondition) (*model.String, error) {
+	var updated model.String
 
-	cmdTag, err := r.db.Pool.Exec(ctx, stmt, pgx.NamedArgs{
-		"string_value": value,
-	})
+	err := pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		before, err := snapshotString(ctx, tx, namespace, hash, liveString)
+		if err != nil {
+			return err
+		}
+
+		if err = checkPrecondition(ctx, tx, namespace, hash, liveString, precondition); err != nil {
+			return err
+		}
+
+		var current map[string]any
+		stmt := `SELECT metadata FROM strings WHERE namespace = @namespace AND sha256_hash = @sha256_hash`
+
+		err = tx.QueryRow(ctx, stmt, pgx.NamedArgs{
+			"namespace":   namespace,
+			"sha256_hash": hash,
+		}).Scan(&current)
+		if err != nil {
+			return fmt.Errorf("failed to read string metadata: %w", err)
+		}
 
+		metadata, ok := util.MergePatch(current, patch).(map[string]any)
+		if !ok {
+			metadata = map[string]any{}
+		}
+
+		stmt = `
+			UPDATE strings
+			SET metadata = @metadata
+			WHERE namespace = @namespace AND sha256_hash = @sha256_hash
+			RETURNING ` + stringColumns
+
+		rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+			"namespace":   namespace,
+			"sha256_hash": hash,
+			"metadata":    metadata,
+		})
+		if err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Patch metadata query failed!")
+			return fmt.Errorf("failed to execute patch metadata query: %w", err)
+		}
+
+		updated, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[model.String])
+		if err != nil {
+			return fmt.Errorf("failed to collect row from table:strings: %w", err)
+		}
+
+		after, err := snapshotString(ctx, tx, namespace, hash, "TRUE")
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, auditEntry{
+			Namespace: namespace,
+			Operation: model.AuditUpdate,
+			Hash:      hash,
+			Before:    before,
+			After:     after,
+		})
+	})
 	if err != nil {
-		r.logger.Error().Err(err).Msg("Delete query failed!")
-		return fmt.Errorf("failed to execute delete string query: %w", err)
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteString moves a string to the trash. With hard set it is removed
+// permanently instead, whether or not it is already in the trash.
+func (r *StringRepository) DeleteString(ctx context.Context, namespace string, value string, hard bool, precondition Precondition) error {
+	hash := util.Hash(value)
+
+	return pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		condition := liveString
+		if hard {
+			condition = notExpired
+		}
+
+		before, err := snapshotString(ctx, tx, namespace, hash, condition)
+		if err != nil {
+			return err
+		}
+
+		if err = checkPrecondition(ctx, tx, namespace, hash, condition, precondition); err != nil {
+			return err
+		}
+
+		entry := auditEntry{
+			Namespace: namespace,
+			Operation: model.AuditDelete,
+			Hash:      hash,
+			Before:    before,
+		}
+
+		if hard {
+			entry.Operation = model.AuditPurge
+			stmt := `DELETE FROM strings WHERE namespace = @namespace AND sha256_hash = @sha256_hash`
+
+			if _, err = tx.Exec(ctx, stmt, pgx.NamedArgs{
+				"namespace":   namespace,
+				"sha256_hash": hash,
+			}); err != nil {
+				requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Delete query failed!")
+				return fmt.Errorf("failed to execute delete string query: %w", err)
+			}
+		} else {
+			stmt := `
+				UPDATE strings
+				SET deleted_at = CURRENT_TIMESTAMP
+				WHERE namespace = @namespace AND sha256_hash = @sha256_hash
+				RETURNING ` + snapshotColumn
+
+			if err = tx.QueryRow(ctx, stmt, pgx.NamedArgs{
+				"namespace":   namespace,
+				"sha256_hash": hash,
+			}).Scan(&entry.After); err != nil {
+				requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Delete query failed!")
+				return fmt.Errorf("failed to execute delete string query: %w", err)
+			}
+		}
+
+		return recordAudit(ctx, tx, entry)
+	})
+}
+
+// checkPrecondition runs precondition, if any, against the string matching
+// condition, which the caller has already locked.
+func checkPrecondition(ctx context.Context, tx pgx.Tx, namespace string, hash string, condition string, precondition Precondition) error {
+	if precondition == nil {
+		return nil
 	}
 
-	if cmdTag.RowsAffected() == 0 {
-		return errs.ErrNotFound
+	stmt := `
+		SELECT ` + stringColumns + `
+		FROM strings
+		WHERE namespace = @namespace AND sha256_hash = @sha256_hash AND ` + condition
+
+	rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+		"namespace":   namespace,
+		"sha256_hash": hash,
+	})
+	if err != nil {
+		return fmt.Errorf("query execution failed: %w", err)
 	}
 
-	return nil
+	current, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.String])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.ErrNotFound
+		}
+		return fmt.Errorf("failed to collect row: %w", err)
+	}
+	return precondition(&current)
 }
 
-func (r *StringRepository) GetFilteredStringsByNaturalLanguage(ctx context.Context, params *dto.FilterParams) ([]model.String, error) {
+func (r *StringRepository) GetFilteredStringsByNaturalLanguage(ctx context.Context, namespace string, params *dto.FilterParams, selection Selection, start func(ResultSummary) error, row func(*model.String) error) error {
 	stmt := `
 		SELECT
-			*
+			` + selectedStringColumns(selection) + `
 		FROM
 			strings
 		WHERE
-			(@is_palindrome::boolean IS NULL OR is_palindrome = @is_palindrome::boolean)
+			namespace = @namespace
+			AND ` + liveString + `
+			AND (@is_palindrome::boolean IS NULL OR is_palindrome = @is_palindrome::boolean)
 			AND (@min_length::int IS NULL OR length >= @min_length::int)
 			AND (@max_length::int IS NULL OR length <= @max_length::int)
 			AND (@word_count::int IS NULL OR word_count = @word_count::int)
 			AND (@contains_character::text IS NULL OR string_value ILIKE '%' || @contains_character || '%')
-		ORDER BY
-			created_at DESC
 	`
 
-	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+	rows, err := r.db.Pool.Query(ctx, summarizedQuery(stmt, "created_at DESC"), pgx.NamedArgs{
+		"namespace": namespace,
 		"is_palindrome": func() any {
 			if params.IsPalindrome == nil {
 				return nil
@@ -227,25 +644,34 @@ func (r *StringRepository) GetFilteredStringsByNaturalLanguage(ctx context.Conte
 	})
 
 	if err != nil {
-		r.logger.Error().
+		requestctx.Logger(ctx, r.logger).Error().
 			Err(err).
 			Interface("params", params).
 			Msg("Natural language filter query failed")
-		return nil, fmt.Errorf("failed to execute natural language filter query: %w", err)
+		return fmt.Errorf("failed to execute natural language filter query: %w", err)
 	}
 
-	records, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.String])
+	type summarizedString struct {
+		model.String
+		ResultSummary
+	}
+	count := 0
+	err = streamSummarized(rows,
+		func(record *summarizedString) ResultSummary {
+			count = record.Count
+			return record.ResultSummary
+		},
+		start,
+		func(record *summarizedString) error { return row(&record.String) },
+	)
 	if err != nil {
-		r.logger.Error().
-			Err(err).
-			Msg("Failed to collect rows from natural language query")
-		return nil, fmt.Errorf("failed to collect rows from table:strings: %w", err)
+		return err
 	}
 
-	r.logger.Info().
-		Int("count", len(records)).
+	requestctx.Logger(ctx, r.logger).Info().
+		Int("count", count).
 		Interface("filters", params).
 		Msg("Natural language query executed successfully")
 
-	return records, nil
+	return nil
 }
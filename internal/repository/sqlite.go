@@ -0,0 +1,838 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/database"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteNow renders the current time in database.SQLiteTimeFormat.
+const sqliteNow = `strftime('%Y-%m-%dT%H:%M:%fZ', 'now')`
+
+// SQLite counterparts of the Postgres row conditions.
+const (
+	sqliteNotExpired    = `(s.expires_at IS NULL OR s.expires_at > ` + sqliteNow + `)`
+	sqliteLiveString    = `s.deleted_at IS NULL AND ` + sqliteNotExpired
+	sqliteTrashedString = `s.deleted_at IS NOT NULL AND ` + sqliteNotExpired
+)
+
+// sqliteStringColumns selects the columns scanned by scanSQLiteString from
+// strings aliased as s. Tags and collections come back as JSON arrays.
+const sqliteStringColumns = sqliteOwnColumns + `,` + sqliteTagsColumn + `,` + sqliteCollectionsColumn
+
+const sqliteOwnColumns = `
+	s.namespace,
+	s.created_at,
+	s.string_value,
+	s.is_palindrome,
+	s.unique_characters,
+	s.word_count,
+	s.sha256_hash,
+	s.length,
+	s.metadata,
+	s.deleted_at,
+	s.expires_at
+`
+
+const sqliteTagsColumn = `
+	(
+		SELECT json_group_array(tag) FROM (
+			SELECT t.tag FROM string_tags t
+			WHERE t.namespace = s.namespace AND t.sha256_hash = s.sha256_hash
+			ORDER BY t.tag
+		)
+	) AS tags
+`
+
+const sqliteCollectionsColumn = `
+	(
+		SELECT json_group_array(collection_name) FROM (
+			SELECT c.collection_name FROM collection_strings c
+			WHERE c.namespace = s.namespace AND c.sha256_hash = s.sha256_hash
+			ORDER BY c.collection_name
+		)
+	) AS collections
+`
+
+// sqliteSelectedColumns is sqliteStringColumns with the link tables only
+// queried for the parts selection asks for. The others decode as nil.
+func sqliteSelectedColumns(selection Selection) string {
+	tags, collections := `'null' AS tags`, `'null' AS collections`
+	if selection.Tags {
+		tags = sqliteTagsColumn
+	}
+	if selection.Collections {
+		collections = sqliteCollectionsColumn
+	}
+	return sqliteOwnColumns + `, ` + tags + `, ` + collections
+}
+
+// sqliteSummarizedQuery wraps stmt, which selects sqliteStringColumns
+// followed by the extra columns, so that each row also carries the
+// ResultSummary of the whole result. SQLite cannot cast a row to text, so
+// the digest hashes the columns by name.
+func sqliteSummarizedQuery(stmt string, extra []string, orderBy string) string {
+	columns := []string{
+		"namespace", "created_at", "string_value", "sha256_hash", "metadata",
+		"deleted_at", "expires_at", "tags", "collections",
+	}
+	columns = append(columns, extra...)
+
+	return `
+		SELECT
+			results.*,
+			COUNT(*) OVER () AS result_count,
+			SUM(row_digest(results.` + strings.Join(columns, ", results.") + `)) OVER () AS result_digest
+		FROM (` + stmt + `) results
+		ORDER BY
+			` + orderBy
+}
+
+// streamSQLiteSummarized reads the rows of a sqliteSummarizedQuery with
+// scan, which fills in the summary the row carries. It calls start with the
+// summary of the first row, or an empty one when there are no rows, then
+// row with each of them.
+func streamSQLiteSummarized[T any](rows *sql.Rows, scan func(rows *sql.Rows, summary *ResultSummary) (T, error), start func(ResultSummary) error, row func(*T) error) error {
+	defer rows.Close()
+
+	started := false
+	for rows.Next() {
+		var summary ResultSummary
+		record, err := scan(rows, &summary)
+		if err != nil {
+			return fmt.Errorf("failed to collect row from table:strings: %w", err)
+		}
+		if !started {
+			started = true
+			if err := start(summary); err != nil {
+				return err
+			}
+		}
+		if err := row(&record); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to collect row from table:strings: %w", err)
+	}
+
+	if !started {
+		return start(ResultSummary{})
+	}
+	return nil
+}
+
+// sqliteSnapshotColumn renders a strings row aliased as s the way
+// to_jsonb(strings) does in Postgres, for the audit log.
+const sqliteSnapshotColumn = `json_object(
+	'id', s.id,
+	'namespace', s.namespace,
+	'created_at', s.created_at,
+	'updated_at', s.updated_at,
+	'string_value', s.string_value,
+	'is_palindrome', json(iif(s.is_palindrome, 'true', 'false')),
+	'unique_characters', s.unique_characters,
+	'word_count', s.word_count,
+	'sha256_hash', s.sha256_hash,
+	'length', s.length,
+	'metadata', json(s.metadata),
+	'deleted_at', s.deleted_at,
+	'expires_at', s.expires_at
+)`
+
+// SQLiteStore implements every store on a SQLite database. Full-text search
+// uses FTS5 rather than Postgres text search, so ranks are on a different
+// scale and snippets may differ; filters behave the same.
+type SQLiteStore struct {
+	logger *zerolog.Logger
+	db     *database.Database
+}
+
+func NewSQLiteStore(logger *zerolog.Logger, db *database.Database) *SQLiteStore {
+	return &SQLiteStore{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// withTx runs fn in a transaction, committing if it returns nil.
+func (r *SQLiteStore) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.SQL.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// querier is the part of *sql.DB and *sql.Tx the helpers below need.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// sqliteCode returns the extended result code of a SQLite error, or 0.
+func sqliteCode(err error) int {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code()
+	}
+	return 0
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	code := sqliteCode(err)
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+func sqliteTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(database.SQLiteTimeFormat)
+}
+
+func parseSQLiteTime(value sql.NullString) (*time.Time, error) {
+	if !value.Valid {
+		return nil, nil
+	}
+
+	t, err := time.Parse(database.SQLiteTimeFormat, value.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp %q: %w", value.String, err)
+	}
+	return &t, nil
+}
+
+func sqliteJSON(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode JSON parameter: %w", err)
+	}
+	return string(b), nil
+}
+
+// scanSQLiteString scans sqliteStringColumns followed by any extra columns.
+func scanSQLiteString(row interface{ Scan(...any) error }, extra ...any) (model.String, error) {
+	var (
+		record                          model.String
+		createdAt, deletedAt, expiresAt sql.NullString
+		metadata, tags, collections     string
+	)
+
+	dest := append([]any{
+		&record.Namespace,
+		&createdAt,
+		&record.StringValue,
+		&record.IsPalindrome,
+		&record.UniqueCharacters,
+		&record.WordCount,
+		&record.Hash,
+		&record.Length,
+		&metadata,
+		&deletedAt,
+		&expiresAt,
+		&tags,
+		&collections,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return record, err
+	}
+
+	created, err := parseSQLiteTime(createdAt)
+	if err != nil {
+		return record, err
+	}
+	record.CreatedAt = *created
+
+	if record.DeletedAt, err = parseSQLiteTime(deletedAt); err != nil {
+		return record, err
+	}
+	if record.ExpiresAt, err = parseSQLiteTime(expiresAt); err != nil {
+		return record, err
+	}
+
+	if err = json.Unmarshal([]byte(metadata), &record.Metadata); err != nil {
+		return record, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	if err = json.Unmarshal([]byte(tags), &record.Tags); err != nil {
+		return record, fmt.Errorf("failed to decode tags: %w", err)
+	}
+	if err = json.Unmarshal([]byte(collections), &record.Collections); err != nil {
+		return record, fmt.Errorf("failed to decode collections: %w", err)
+	}
+
+	return record, nil
+}
+
+func collectSQLiteStrings(rows *sql.Rows) ([]model.String, error) {
+	defer rows.Close()
+
+	records := []model.String{}
+	for rows.Next() {
+		record, err := scanSQLiteString(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// getSQLiteString reads one string in the namespace matching condition. It
+// returns errs.ErrNotFound when there is none.
+func getSQLiteString(ctx context.Context, q querier, namespace string, hash string, condition string) (*model.String, error) {
+	stmt := `
+		SELECT
+			` + sqliteStringColumns + `
+		FROM
+			strings s
+		WHERE
+			s.namespace = @namespace
+			AND s.sha256_hash = @sha256_hash
+			AND ` + condition
+
+	record, err := scanSQLiteString(q.QueryRowContext(ctx, stmt,
+		sql.Named("namespace", namespace),
+		sql.Named("sha256_hash", hash),
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read row from table:strings: %w", err)
+	}
+
+	return &record, nil
+}
+
+// checkSQLitePrecondition runs precondition, if any, against the string
+// matching condition.
+func checkSQLitePrecondition(ctx context.Context, tx *sql.Tx, namespace string, hash string, condition string, precondition Precondition) error {
+	if precondition == nil {
+		return nil
+	}
+
+	current, err := getSQLiteString(ctx, tx, namespace, hash, condition)
+	if err != nil {
+		return err
+	}
+	return precondition(current)
+}
+
+// ftsQuery translates a parsed web search query into FTS5 MATCH expressions:
+// one every result must match and one no result may match. Either is nil
+// when the query has no terms of that kind.
+func ftsQuery(q searchQuery) (match any, exclude any) {
+	phrases := func(terms [][]string, op string) any {
+		if len(terms) == 0 {
+			return nil
+		}
+
+		quoted := make([]string, len(terms))
+		for i, phrase := range terms {
+			quoted[i] = `"` + strings.Join(phrase, " ") + `"`
+		}
+		return strings.Join(quoted, op)
+	}
+
+	return phrases(q.include, " AND "), phrases(q.exclude, " OR ")
+}
+
+func (r *SQLiteStore) GetFilteredStrings(ctx context.Context, namespace string, params dto.QueryParams, selection Selection, start func(ResultSummary) error, row func(*model.StringMatch) error) error {
+	search := parseSearchQuery(params.Query)
+	match, exclude := ftsQuery(search)
+	if params.Query != "" && match == nil && exclude == nil {
+		// Like websearch_to_tsquery, a query with no searchable terms
+		// matches nothing.
+		return start(ResultSummary{})
+	}
+
+	// FTS5 rejects a NULL MATCH operand even when the surrounding condition
+	// could skip it, so the search clauses are only added when needed.
+	rank, snippet, searchJoin, searchFilter := "0", "''", "", ""
+	if match != nil {
+		rank, snippet = "search.rank", "search.snippet"
+		highlight := "''"
+		if selection.Snippet {
+			highlight = "snippet(strings_fts, 0, '<mark>', '</mark>', '...', 20)"
+		}
+		searchJoin = `
+			JOIN (
+				SELECT
+					rowid AS id,
+					-bm25(strings_fts) AS rank,
+					` + highlight + ` AS snippet
+				FROM strings_fts
+				WHERE strings_fts MATCH @match
+			) search ON search.id = s.id`
+	}
+	if exclude != nil {
+		searchFilter = `
+			AND s.id NOT IN (SELECT rowid FROM strings_fts WHERE strings_fts MATCH @exclude)`
+	}
+
+	stmt := `
+		SELECT
+			` + sqliteSelectedColumns(selection) + `,
+			` + rank + ` AS rank,
+			` + snippet + ` AS snippet
+		FROM
+			strings s` + searchJoin + `
+		WHERE
+			s.namespace = @namespace
+			AND ` + sqliteLiveString + searchFilter + `
+			AND (@is_palindrome IS NULL OR s.is_palindrome = @is_palindrome)
+			AND (@min_length IS NULL OR s.length >= @min_length)
+			AND (@max_length IS NULL OR s.length <= @max_length)
+			AND (@word_count IS NULL OR s.word_count = @word_count)
+			AND (@contains_character IS NULL OR s.folded_value LIKE '%' || @contains_character || '%')
+			AND (@tags IS NULL OR NOT EXISTS (
+				SELECT 1 FROM json_each(@tags) j
+				WHERE j.value NOT IN (
+					SELECT t.tag FROM string_tags t
+					WHERE t.namespace = s.namespace AND t.sha256_hash = s.sha256_hash
+				)
+			))
+			AND (@metadata IS NULL OR json_contains(s.metadata, @metadata))
+			AND (@metadata_keys IS NULL OR NOT EXISTS (
+				SELECT 1 FROM json_each(@metadata_keys) k
+				WHERE k.value NOT IN (SELECT m.key FROM json_each(s.metadata) m)
+			))
+			AND (@collection IS NULL OR EXISTS (
+				SELECT 1 FROM collection_strings c
+				WHERE c.namespace = s.namespace
+					AND c.collection_name = @collection
+					AND c.sha256_hash = s.sha256_hash
+			))
+	`
+
+	var tags, metadata, metadataKeys any
+	var err error
+	if len(params.Tags) > 0 {
+		if tags, err = sqliteJSON(params.Tags); err != nil {
+			return err
+		}
+	}
+	if len(params.Metadata) > 0 {
+		if metadata, err = sqliteJSON(params.Metadata); err != nil {
+			return err
+		}
+	}
+	if len(params.MetadataKeys) > 0 {
+		if metadataKeys, err = sqliteJSON(params.MetadataKeys); err != nil {
+			return err
+		}
+	}
+
+	rows, err := r.db.SQL.QueryContext(ctx, sqliteSummarizedQuery(stmt, []string{"rank", "snippet"}, "rank DESC, created_at"),
+		sql.Named("namespace", namespace),
+		sql.Named("match", match),
+		sql.Named("exclude", exclude),
+		sql.Named("is_palindrome", func() any {
+			if params.IsPalindrome == nil {
+				return nil
+			}
+			return *params.IsPalindrome
+		}()),
+		sql.Named("min_length", func() any {
+			if params.MinLength == nil {
+				return nil
+			}
+			return *params.MinLength
+		}()),
+		sql.Named("max_length", func() any {
+			if params.MaxLength == nil {
+				return nil
+			}
+			return *params.MaxLength
+		}()),
+		sql.Named("word_count", func() any {
+			if params.WordCount == nil {
+				return nil
+			}
+			return *params.WordCount
+		}()),
+		sql.Named("contains_character", nullable(strings.ToLower(params.ContainsCharacter))),
+		sql.Named("tags", tags),
+		sql.Named("metadata", metadata),
+		sql.Named("metadata_keys", metadataKeys),
+		sql.Named("collection", nullable(params.Collection)),
+	)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Query Failed!")
+		return fmt.Errorf("failed to execute string query: %w", err)
+	}
+
+	return streamSQLiteSummarized(rows, func(rows *sql.Rows, summary *ResultSummary) (model.StringMatch, error) {
+		var match model.StringMatch
+		var err error
+		match.String, err = scanSQLiteString(rows, &match.Rank, &match.Snippet, &summary.Count, &summary.Digest)
+		return match, err
+	}, start, row)
+}
+
+func (r *SQLiteStore) GetStringByValue(ctx context.Context, namespace string, value string) (*model.String, error) {
+	return r.GetStringByHash(ctx, namespace, util.Hash(value))
+}
+
+func (r *SQLiteStore) GetStringByHash(ctx context.Context, namespace string, hash string) (*model.String, error) {
+	record, err := getSQLiteString(ctx, r.db.SQL, namespace, hash, sqliteLiveString)
+	if err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, nil // no match found, return nil without error
+		}
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// CreateString stores a new string, replacing a trashed or expired copy of
+// the same value as the Postgres repository does.
+func (r *SQLiteStore) CreateString(ctx context.Context, namespace string, payload *dto.CreateString) (*model.String, error) {
+	var created *model.String
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		stmt := `
+			SELECT ` + sqliteSnapshotColumn + `, NOT ` + sqliteNotExpired + `
+			FROM strings s
+			WHERE s.namespace = @namespace AND s.sha256_hash = @sha256_hash
+				AND NOT (` + sqliteLiveString + `)
+		`
+
+		var (
+			replaced string
+			expired  bool
+		)
+		err := tx.QueryRowContext(ctx, stmt,
+			sql.Named("namespace", namespace),
+			sql.Named("sha256_hash", payload.Hash),
+		).Scan(&replaced, &expired)
+		switch {
+		case err == nil:
+			stmt = `DELETE FROM strings WHERE namespace = @namespace AND sha256_hash = @sha256_hash`
+			if _, err = tx.ExecContext(ctx, stmt,
+				sql.Named("namespace", namespace),
+				sql.Named("sha256_hash", payload.Hash),
+			); err != nil {
+				return fmt.Errorf("failed to clear trashed string: %w", err)
+			}
+
+			operation := model.AuditPurge
+			if expired {
+				operation = model.AuditExpire
+			}
+			if err = recordSQLiteAudit(ctx, tx, auditEntry{
+				Namespace: namespace,
+				Operation: operation,
+				Hash:      payload.Hash,
+				Before:    []byte(replaced),
+			}); err != nil {
+				return err
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("failed to clear trashed string: %w", err)
+		}
+
+		metadata := payload.Metadata
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+		encoded, err := sqliteJSON(metadata)
+		if err != nil {
+			return err
+		}
+
+		stmt = `
+			INSERT INTO strings (
+				namespace,
+				string_value,
+				folded_value,
+				is_palindrome,
+				unique_characters,
+				word_count,
+				sha256_hash,
+				length,
+				metadata,
+				expires_at
+			)
+			VALUES (
+				@namespace,
+				@string_value,
+				@folded_value,
+				@is_palindrome,
+				@unique_characters,
+				@word_count,
+				@sha256_hash,
+				@length,
+				@metadata,
+				@expires_at
+			)
+		`
+
+		if _, err = tx.ExecContext(ctx, stmt,
+			sql.Named("namespace", namespace),
+			sql.Named("string_value", payload.StringValue),
+			sql.Named("folded_value", strings.ToLower(payload.StringValue)),
+			sql.Named("is_palindrome", payload.IsPalindrome),
+			sql.Named("unique_characters", payload.UniqueCharacters),
+			sql.Named("word_count", payload.WordCount),
+			sql.Named("sha256_hash", payload.Hash),
+			sql.Named("length", payload.Length),
+			sql.Named("metadata", encoded),
+			sql.Named("expires_at", sqliteTime(payload.ExpiresAt)),
+		); err != nil {
+			switch sqliteCode(err) {
+			case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+				return errs.ErrNamespaceNotFound
+			case sqlite3.SQLITE_CONSTRAINT_UNIQUE:
+				return errs.ErrAlreadyExists
+			}
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Query Failed!")
+			return fmt.Errorf("failed to execute create string query: %w", err)
+		}
+
+		after, err := snapshotSQLiteString(ctx, tx, namespace, payload.Hash, "TRUE")
+		if err != nil {
+			return err
+		}
+
+		if err = recordSQLiteAudit(ctx, tx, auditEntry{
+			Namespace: namespace,
+			Operation: model.AuditCreate,
+			Hash:      payload.Hash,
+			After:     after,
+		}); err != nil {
+			return err
+		}
+
+		created, err = getSQLiteString(ctx, tx, namespace, payload.Hash, "TRUE")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r *SQLiteStore) PatchMetadata(ctx context.Context, namespace string, hash string, patch any, precondition Precondition) (*model.String, error) {
+	var updated *model.String
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		before, err := snapshotSQLiteString(ctx, tx, namespace, hash, sqliteLiveString)
+		if err != nil {
+			return err
+		}
+
+		if err = checkSQLitePrecondition(ctx, tx, namespace, hash, sqliteLiveString, precondition); err != nil {
+			return err
+		}
+
+		var raw string
+		stmt := `SELECT metadata FROM strings WHERE namespace = @namespace AND sha256_hash = @sha256_hash`
+
+		if err = tx.QueryRowContext(ctx, stmt,
+			sql.Named("namespace", namespace),
+			sql.Named("sha256_hash", hash),
+		).Scan(&raw); err != nil {
+			return fmt.Errorf("failed to read string metadata: %w", err)
+		}
+
+		var current map[string]any
+		if err = json.Unmarshal([]byte(raw), &current); err != nil {
+			return fmt.Errorf("failed to decode string metadata: %w", err)
+		}
+
+		metadata, ok := util.MergePatch(current, patch).(map[string]any)
+		if !ok {
+			metadata = map[string]any{}
+		}
+		encoded, err := sqliteJSON(metadata)
+		if err != nil {
+			return err
+		}
+
+		stmt = `UPDATE strings SET metadata = @metadata WHERE namespace = @namespace AND sha256_hash = @sha256_hash`
+
+		if _, err = tx.ExecContext(ctx, stmt,
+			sql.Named("namespace", namespace),
+			sql.Named("sha256_hash", hash),
+			sql.Named("metadata", encoded),
+		); err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Patch metadata query failed!")
+			return fmt.Errorf("failed to execute patch metadata query: %w", err)
+		}
+
+		after, err := snapshotSQLiteString(ctx, tx, namespace, hash, "TRUE")
+		if err != nil {
+			return err
+		}
+
+		if err = recordSQLiteAudit(ctx, tx, auditEntry{
+			Namespace: namespace,
+			Operation: model.AuditUpdate,
+			Hash:      hash,
+			Before:    before,
+			After:     after,
+		}); err != nil {
+			return err
+		}
+
+		updated, err = getSQLiteString(ctx, tx, namespace, hash, "TRUE")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (r *SQLiteStore) DeleteString(ctx context.Context, namespace string, value string, hard bool, precondition Precondition) error {
+	hash := util.Hash(value)
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		condition := sqliteLiveString
+		if hard {
+			condition = sqliteNotExpired
+		}
+
+		before, err := snapshotSQLiteString(ctx, tx, namespace, hash, condition)
+		if err != nil {
+			return err
+		}
+
+		if err = checkSQLitePrecondition(ctx, tx, namespace, hash, condition, precondition); err != nil {
+			return err
+		}
+
+		entry := auditEntry{
+			Namespace: namespace,
+			Operation: model.AuditDelete,
+			Hash:      hash,
+			Before:    before,
+		}
+
+		if hard {
+			entry.Operation = model.AuditPurge
+			stmt := `DELETE FROM strings WHERE namespace = @namespace AND sha256_hash = @sha256_hash`
+
+			if _, err = tx.ExecContext(ctx, stmt,
+				sql.Named("namespace", namespace),
+				sql.Named("sha256_hash", hash),
+			); err != nil {
+				requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Delete query failed!")
+				return fmt.Errorf("failed to execute delete string query: %w", err)
+			}
+		} else {
+			stmt := `
+				UPDATE strings
+				SET deleted_at = ` + sqliteNow + `
+				WHERE namespace = @namespace AND sha256_hash = @sha256_hash
+			`
+
+			if _, err = tx.ExecContext(ctx, stmt,
+				sql.Named("namespace", namespace),
+				sql.Named("sha256_hash", hash),
+			); err != nil {
+				requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Delete query failed!")
+				return fmt.Errorf("failed to execute delete string query: %w", err)
+			}
+
+			if entry.After, err = snapshotSQLiteString(ctx, tx, namespace, hash, "TRUE"); err != nil {
+				return err
+			}
+		}
+
+		return recordSQLiteAudit(ctx, tx, entry)
+	})
+}
+
+func (r *SQLiteStore) GetFilteredStringsByNaturalLanguage(ctx context.Context, namespace string, params *dto.FilterParams, selection Selection, start func(ResultSummary) error, row func(*model.String) error) error {
+	stmt := `
+		SELECT
+			` + sqliteSelectedColumns(selection) + `
+		FROM
+			strings s
+		WHERE
+			s.namespace = @namespace
+			AND ` + sqliteLiveString + `
+			AND (@is_palindrome IS NULL OR s.is_palindrome = @is_palindrome)
+			AND (@min_length IS NULL OR s.length >= @min_length)
+			AND (@max_length IS NULL OR s.length <= @max_length)
+			AND (@word_count IS NULL OR s.word_count = @word_count)
+			AND (@contains_character IS NULL OR s.folded_value LIKE '%' || @contains_character || '%')
+	`
+
+	rows, err := r.db.SQL.QueryContext(ctx, sqliteSummarizedQuery(stmt, nil, "created_at DESC"),
+		sql.Named("namespace", namespace),
+		sql.Named("is_palindrome", func() any {
+			if params.IsPalindrome == nil {
+				return nil
+			}
+			return *params.IsPalindrome
+		}()),
+		sql.Named("min_length", func() any {
+			if params.MinLength == nil {
+				return nil
+			}
+			return *params.MinLength
+		}()),
+		sql.Named("max_length", func() any {
+			if params.MaxLength == nil {
+				return nil
+			}
+			return *params.MaxLength
+		}()),
+		sql.Named("word_count", func() any {
+			if params.WordCount == nil {
+				return nil
+			}
+			return *params.WordCount
+		}()),
+		sql.Named("contains_character", func() any {
+			if params.ContainsCharacter == nil {
+				return nil
+			}
+			return strings.ToLower(*params.ContainsCharacter)
+		}()),
+	)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().
+			Err(err).
+			Interface("params", params).
+			Msg("Natural language filter query failed")
+		return fmt.Errorf("failed to execute natural language filter query: %w", err)
+	}
+
+	return streamSQLiteSummarized(rows, func(rows *sql.Rows, summary *ResultSummary) (model.String, error) {
+		return scanSQLiteString(rows, &summary.Count, &summary.Digest)
+	}, start, row)
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+const sqliteAPIKeyColumns = `id, name, prefix, scopes, created_at, revoked_at`
+
+func scanSQLiteAPIKey(row interface{ Scan(...any) error }) (model.APIKey, error) {
+	var (
+		key                  model.APIKey
+		scopes               string
+		createdAt, revokedAt sql.NullString
+	)
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &scopes, &createdAt, &revokedAt); err != nil {
+		return key, err
+	}
+
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return key, fmt.Errorf("failed to decode api key scopes: %w", err)
+	}
+
+	created, err := parseSQLiteTime(createdAt)
+	if err != nil {
+		return key, err
+	}
+	key.CreatedAt = *created
+
+	if key.RevokedAt, err = parseSQLiteTime(revokedAt); err != nil {
+		return key, err
+	}
+
+	return key, nil
+}
+
+func (r *SQLiteStore) ListAPIKeys(ctx context.Context) ([]model.APIKey, error) {
+	rows, err := r.db.SQL.QueryContext(ctx, `SELECT `+sqliteAPIKeyColumns+` FROM api_keys ORDER BY id`)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List api keys query failed!")
+		return nil, fmt.Errorf("failed to execute list api keys query: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []model.APIKey{}
+	for rows.Next() {
+		key, err := scanSQLiteAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect rows from table:api_keys: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:api_keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *SQLiteStore) GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	stmt := `SELECT ` + sqliteAPIKeyColumns + ` FROM api_keys WHERE key_hash = @key_hash AND revoked_at IS NULL`
+
+	key, err := scanSQLiteAPIKey(r.db.SQL.QueryRowContext(ctx, stmt, sql.Named("key_hash", hash)))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (r *SQLiteStore) CreateAPIKey(ctx context.Context, payload *dto.StoreAPIKey) (*model.APIKey, error) {
+	scopes, err := sqliteJSON(payload.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes)
+		VALUES (@name, @prefix, @key_hash, @scopes)
+		RETURNING ` + sqliteAPIKeyColumns
+
+	key, err := scanSQLiteAPIKey(r.db.SQL.QueryRowContext(ctx, stmt,
+		sql.Named("name", payload.Name),
+		sql.Named("prefix", payload.Prefix),
+		sql.Named("key_hash", payload.Hash),
+		sql.Named("scopes", scopes),
+	))
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Create api key query failed!")
+		return nil, fmt.Errorf("failed to execute create api key query: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (r *SQLiteStore) RevokeAPIKey(ctx context.Context, id int64) error {
+	stmt := `
+		UPDATE api_keys
+		SET revoked_at = ` + sqliteNow + `
+		WHERE id = @id AND revoked_at IS NULL
+	`
+
+	result, err := r.db.SQL.ExecContext(ctx, stmt, sql.Named("id", id))
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Revoke api key query failed!")
+		return fmt.Errorf("failed to execute revoke api key query: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errs.ErrAPIKeyNotFound
+	}
+
+	return nil
+}
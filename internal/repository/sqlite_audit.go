@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+func recordSQLiteAudit(ctx context.Context, tx *sql.Tx, entry auditEntry) error {
+	stmt := `
+		INSERT INTO audit_events (namespace, actor, request_id, operation, sha256_hash, before, after)
+		VALUES (@namespace, @actor, @request_id, @operation, @sha256_hash, @before, @after)
+	`
+
+	_, err := tx.ExecContext(ctx, stmt,
+		sql.Named("namespace", entry.Namespace),
+		sql.Named("actor", requestctx.Actor(ctx)),
+		sql.Named("request_id", nullable(requestctx.RequestID(ctx))),
+		sql.Named("operation", entry.Operation),
+		sql.Named("sha256_hash", nullable(entry.Hash)),
+		sql.Named("before", nullableJSON(entry.Before)),
+		sql.Named("after", nullableJSON(entry.After)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	return nil
+}
+
+// snapshotSQLiteString returns a string row as JSON. The write lock taken by
+// the transaction stands in for Postgres' FOR UPDATE. It returns
+// errs.ErrNotFound when no row matches condition.
+func snapshotSQLiteString(ctx context.Context, tx *sql.Tx, namespace string, hash string, condition string) ([]byte, error) {
+	stmt := `
+		SELECT ` + sqliteSnapshotColumn + `
+		FROM strings s
+		WHERE s.namespace = @namespace AND s.sha256_hash = @sha256_hash AND ` + condition
+
+	var snapshot string
+	err := tx.QueryRowContext(ctx, stmt,
+		sql.Named("namespace", namespace),
+		sql.Named("sha256_hash", hash),
+	).Scan(&snapshot)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to snapshot string: %w", err)
+	}
+
+	return []byte(snapshot), nil
+}
+
+func (r *SQLiteStore) ListAuditEvents(ctx context.Context, params dto.AuditQuery) ([]model.AuditEvent, error) {
+	stmt := `
+		SELECT
+			id,
+			occurred_at,
+			namespace,
+			actor,
+			request_id,
+			operation,
+			sha256_hash,
+			before,
+			after
+		FROM
+			audit_events
+		WHERE
+			(@namespace IS NULL OR namespace = @namespace)
+			AND (@actor IS NULL OR actor = @actor)
+			AND (@operation IS NULL OR operation = @operation)
+			AND (@sha256_hash IS NULL OR sha256_hash = @sha256_hash)
+			AND (@request_id IS NULL OR request_id = @request_id)
+			AND (@since IS NULL OR occurred_at >= @since)
+			AND (@until IS NULL OR occurred_at < @until)
+			AND (@cursor IS NULL OR id < @cursor)
+		ORDER BY
+			id DESC
+		LIMIT @limit
+	`
+
+	rows, err := r.db.SQL.QueryContext(ctx, stmt,
+		sql.Named("namespace", nullable(params.Namespace)),
+		sql.Named("actor", nullable(params.Actor)),
+		sql.Named("operation", nullable(params.Operation)),
+		sql.Named("sha256_hash", nullable(params.StringID)),
+		sql.Named("request_id", nullable(params.RequestID)),
+		sql.Named("since", sqliteTime(params.Since)),
+		sql.Named("until", sqliteTime(params.Until)),
+		sql.Named("cursor", params.Cursor),
+		sql.Named("limit", params.Limit),
+	)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List audit events query failed!")
+		return nil, fmt.Errorf("failed to execute list audit events query: %w", err)
+	}
+	defer rows.Close()
+
+	events := []model.AuditEvent{}
+	for rows.Next() {
+		var (
+			event                       model.AuditEvent
+			occurredAt, requestID, hash sql.NullString
+			before, after               sql.NullString
+		)
+		if err = rows.Scan(
+			&event.ID,
+			&occurredAt,
+			&event.Namespace,
+			&event.Actor,
+			&requestID,
+			&event.Operation,
+			&hash,
+			&before,
+			&after,
+		); err != nil {
+			return nil, fmt.Errorf("failed to collect rows from table:audit_events: %w", err)
+		}
+
+		occurred, err := parseSQLiteTime(occurredAt)
+		if err != nil {
+			return nil, err
+		}
+		event.OccurredAt = *occurred
+
+		if requestID.Valid {
+			event.RequestID = &requestID.String
+		}
+		if hash.Valid {
+			event.StringID = &hash.String
+		}
+		if before.Valid {
+			event.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			event.After = json.RawMessage(after.String)
+		}
+
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:audit_events: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+// sqliteStringExists reports errs.ErrNotFound when no live string with the
+// given hash is stored in the namespace.
+func sqliteStringExists(ctx context.Context, tx *sql.Tx, namespace string, hash string) error {
+	var exists bool
+	stmt := `
+		SELECT EXISTS (
+			SELECT 1 FROM strings s
+			WHERE s.namespace = @namespace AND s.sha256_hash = @sha256_hash AND ` + sqliteLiveString + `
+		)
+	`
+	err := tx.QueryRowContext(ctx, stmt,
+		sql.Named("namespace", namespace),
+		sql.Named("sha256_hash", hash),
+	).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check string existence: %w", err)
+	}
+
+	if !exists {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLiteStore) AddTags(ctx context.Context, namespace string, hash string, tags []string) ([]string, error) {
+	result := []string{}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := sqliteStringExists(ctx, tx, namespace, hash); err != nil {
+			return err
+		}
+
+		encoded, err := sqliteJSON(tags)
+		if err != nil {
+			return err
+		}
+
+		stmt := `
+			INSERT OR IGNORE INTO string_tags (namespace, sha256_hash, tag)
+			SELECT @namespace, @sha256_hash, value FROM json_each(@tags)
+		`
+
+		if _, err = tx.ExecContext(ctx, stmt,
+			sql.Named("namespace", namespace),
+			sql.Named("sha256_hash", hash),
+			sql.Named("tags", encoded),
+		); err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Add tags query failed!")
+			return fmt.Errorf("failed to execute add tags query: %w", err)
+		}
+
+		stmt = `SELECT tag FROM string_tags WHERE namespace = @namespace AND sha256_hash = @sha256_hash ORDER BY tag`
+		rows, err := tx.QueryContext(ctx, stmt,
+			sql.Named("namespace", namespace),
+			sql.Named("sha256_hash", hash),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to execute list tags query: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var tag string
+			if err = rows.Scan(&tag); err != nil {
+				return fmt.Errorf("failed to collect rows from table:string_tags: %w", err)
+			}
+			result = append(result, tag)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *SQLiteStore) RemoveTag(ctx context.Context, namespace string, hash string, tag string) error {
+	stmt := `DELETE FROM string_tags WHERE namespace = @namespace AND sha256_hash = @sha256_hash AND tag = @tag`
+
+	result, err := r.db.SQL.ExecContext(ctx, stmt,
+		sql.Named("namespace", namespace),
+		sql.Named("sha256_hash", hash),
+		sql.Named("tag", tag),
+	)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Remove tag query failed!")
+		return fmt.Errorf("failed to execute remove tag query: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLiteStore) ListCollections(ctx context.Context, namespace string) ([]model.Collection, error) {
+	stmt := `
+		SELECT
+			c.name,
+			c.created_at,
+			COUNT(s.sha256_hash) AS string_count
+		FROM
+			collections c
+			LEFT JOIN collection_strings cs
+				ON cs.namespace = c.namespace AND cs.collection_name = c.name
+			LEFT JOIN strings s
+				ON s.namespace = cs.namespace AND s.sha256_hash = cs.sha256_hash
+				AND ` + sqliteLiveString + `
+		WHERE
+			c.namespace = @namespace
+		GROUP BY
+			c.name, c.created_at
+		ORDER BY
+			c.name
+	`
+
+	rows, err := r.db.SQL.QueryContext(ctx, stmt, sql.Named("namespace", namespace))
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List collections query failed!")
+		return nil, fmt.Errorf("failed to execute list collections query: %w", err)
+	}
+	defer rows.Close()
+
+	collections := []model.Collection{}
+	for rows.Next() {
+		var (
+			collection model.Collection
+			createdAt  sql.NullString
+		)
+		if err = rows.Scan(&collection.Name, &createdAt, &collection.StringCount); err != nil {
+			return nil, fmt.Errorf("failed to collect rows from table:collections: %w", err)
+		}
+
+		created, err := parseSQLiteTime(createdAt)
+		if err != nil {
+			return nil, err
+		}
+		collection.CreatedAt = *created
+
+		collections = append(collections, collection)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:collections: %w", err)
+	}
+
+	return collections, nil
+}
+
+func (r *SQLiteStore) CreateCollection(ctx context.Context, namespace string, name string) (*model.Collection, error) {
+	stmt := `
+		INSERT INTO collections (namespace, name)
+		VALUES (@namespace, @name)
+		RETURNING created_at
+	`
+
+	var createdAt sql.NullString
+	err := r.db.SQL.QueryRowContext(ctx, stmt,
+		sql.Named("namespace", namespace),
+		sql.Named("name", name),
+	).Scan(&createdAt)
+	if err != nil {
+		if isSQLiteUniqueViolation(err) {
+			return nil, errs.ErrAlreadyExists
+		}
+
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Create collection query failed!")
+		return nil, fmt.Errorf("failed to execute create collection query: %w", err)
+	}
+
+	created, err := parseSQLiteTime(createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Collection{Name: name, CreatedAt: *created}, nil
+}
+
+func (r *SQLiteStore) DeleteCollection(ctx context.Context, namespace string, name string) error {
+	stmt := `DELETE FROM collections WHERE namespace = @namespace AND name = @name`
+
+	result, err := r.db.SQL.ExecContext(ctx, stmt,
+		sql.Named("namespace", namespace),
+		sql.Named("name", name),
+	)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Delete collection query failed!")
+		return fmt.Errorf("failed to execute delete collection query: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errs.ErrCollectionNotFound
+	}
+
+	return nil
+}
+
+func (r *SQLiteStore) AddToCollection(ctx context.Context, namespace string, name string, hash string) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		stmt := `SELECT EXISTS (SELECT 1 FROM collections WHERE namespace = @namespace AND name = @name)`
+		err := tx.QueryRowContext(ctx, stmt,
+			sql.Named("namespace", namespace),
+			sql.Named("name", name),
+		).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("failed to check collection existence: %w", err)
+		}
+		if !exists {
+			return errs.ErrCollectionNotFound
+		}
+
+		if err = sqliteStringExists(ctx, tx, namespace, hash); err != nil {
+			return err
+		}
+
+		stmt = `
+			INSERT OR IGNORE INTO collection_strings (namespace, collection_name, sha256_hash)
+			VALUES (@namespace, @name, @sha256_hash)
+		`
+
+		if _, err = tx.ExecContext(ctx, stmt,
+			sql.Named("namespace", namespace),
+			sql.Named("name", name),
+			sql.Named("sha256_hash", hash),
+		); err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Add to collection query failed!")
+			return fmt.Errorf("failed to execute add to collection query: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *SQLiteStore) RemoveFromCollection(ctx context.Context, namespace string, name string, hash string) error {
+	stmt := `
+		DELETE FROM collection_strings
+		WHERE namespace = @namespace AND collection_name = @name AND sha256_hash = @sha256_hash
+	`
+
+	result, err := r.db.SQL.ExecContext(ctx, stmt,
+		sql.Named("namespace", namespace),
+		sql.Named("name", name),
+		sql.Named("sha256_hash", hash),
+	)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Remove from collection query failed!")
+		return fmt.Errorf("failed to execute remove from collection query: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
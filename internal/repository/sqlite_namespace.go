@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+func (r *SQLiteStore) ListNamespaces(ctx context.Context) ([]model.Namespace, error) {
+	stmt := `
+		SELECT
+			n.name,
+			n.created_at,
+			COUNT(s.sha256_hash) AS string_count
+		FROM
+			namespaces n
+			LEFT JOIN strings s ON s.namespace = n.name AND ` + sqliteLiveString + `
+		GROUP BY
+			n.name, n.created_at
+		ORDER BY
+			n.name
+	`
+
+	rows, err := r.db.SQL.QueryContext(ctx, stmt)
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List namespaces query failed!")
+		return nil, fmt.Errorf("failed to execute list namespaces query: %w", err)
+	}
+	defer rows.Close()
+
+	namespaces := []model.Namespace{}
+	for rows.Next() {
+		var (
+			namespace model.Namespace
+			createdAt sql.NullString
+		)
+		if err = rows.Scan(&namespace.Name, &createdAt, &namespace.StringCount); err != nil {
+			return nil, fmt.Errorf("failed to collect rows from table:namespaces: %w", err)
+		}
+
+		created, err := parseSQLiteTime(createdAt)
+		if err != nil {
+			return nil, err
+		}
+		namespace.CreatedAt = *created
+
+		namespaces = append(namespaces, namespace)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:namespaces: %w", err)
+	}
+
+	return namespaces, nil
+}
+
+func (r *SQLiteStore) NamespaceExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+
+	err := r.db.SQL.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM namespaces WHERE name = @name)`,
+		sql.Named("name", name),
+	).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check namespace existence: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *SQLiteStore) CreateNamespace(ctx context.Context, name string) (*model.Namespace, error) {
+	stmt := `
+		INSERT INTO namespaces (name)
+		VALUES (@name)
+		RETURNING created_at
+	`
+
+	var createdAt sql.NullString
+	err := r.db.SQL.QueryRowContext(ctx, stmt, sql.Named("name", name)).Scan(&createdAt)
+	if err != nil {
+		if isSQLiteUniqueViolation(err) {
+			return nil, errs.ErrAlreadyExists
+		}
+
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Create namespace query failed!")
+		return nil, fmt.Errorf("failed to execute create namespace query: %w", err)
+	}
+
+	created, err := parseSQLiteTime(createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Namespace{Name: name, CreatedAt: *created}, nil
+}
+
+// DeleteNamespace removes a namespace and, through the cascading foreign
+// keys, everything stored in it.
+func (r *SQLiteStore) DeleteNamespace(ctx context.Context, name string) error {
+	if name == DefaultNamespace {
+		return errs.ErrDefaultNamespace
+	}
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM namespaces WHERE name = @name`, sql.Named("name", name))
+		if err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Delete namespace query failed!")
+			return fmt.Errorf("failed to execute delete namespace query: %w", err)
+		}
+
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return errs.ErrNamespaceNotFound
+		}
+
+		return recordSQLiteAudit(ctx, tx, auditEntry{
+			Namespace: name,
+			Operation: model.AuditDeleteNamespace,
+		})
+	})
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/database"
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/util"
+	"github.com/rs/zerolog"
+)
+
+// newTestSQLiteStore returns a store on a fresh, migrated SQLite database.
+// The database is a file because the migrations run on a connection of
+// their own, which an in-memory database would not outlive.
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	cfg := &config.Config{
+		Database: config.DatabaseConfig{Driver: config.DriverSQLite, Path: filepath.Join(t.TempDir(), "analyzer.db")},
+		Search:   config.SearchConfig{Language: "english"},
+	}
+	if err := database.Migrate(context.Background(), &logger, cfg); err != nil {
+		t.Fatal(err)
+	}
+	db, err := database.New(cfg, &logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return NewSQLiteStore(&logger, db)
+}
+
+// seedFilterFixtures stores the strings the filter tests query, including
+// one that has expired and one in the trash.
+func seedFilterFixtures(t *testing.T, s StringStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	expired := time.Now().Add(-time.Minute)
+	for _, fixture := range []struct {
+		value     string
+		metadata  map[string]any
+		expiresAt *time.Time
+	}{
+		// Numbers are float64, as metadata decoded from a request is.
+		{"Racecar", map[string]any{"lang": "en", "score": float64(1), "nested": map[string]any{"a": float64(1), "b": float64(2)}}, nil},
+		{"Ärger im Büro", map[string]any{"lang": "de"}, nil},
+		{"100% sure", nil, nil},
+		{"snake_case", map[string]any{"score": float64(2)}, nil},
+		{"expired racecar", map[string]any{"lang": "en"}, &expired},
+		{"deleted racecar", map[string]any{"lang": "en"}, nil},
+	} {
+		_, err := s.CreateString(ctx, DefaultNamespace, &dto.CreateString{
+			StringValue: fixture.value,
+			Hash:        util.Hash(fixture.value),
+			Length:      len([]rune(fixture.value)),
+			Metadata:    fixture.metadata,
+			ExpiresAt:   fixture.expiresAt,
+		})
+		if err != nil {
+			t.Fatalf("creating %q: %v", fixture.value, err)
+		}
+	}
+
+	if _, err := s.AddTags(ctx, DefaultNamespace, util.Hash("Racecar"), []string{"palindrome", "car"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddTags(ctx, DefaultNamespace, util.Hash("snake_case"), []string{"car"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateCollection(ctx, DefaultNamespace, "favourites"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddToCollection(ctx, DefaultNamespace, "favourites", util.Hash("Racecar")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteString(ctx, DefaultNamespace, "deleted racecar", false, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSQLiteFiltersMatchMemoryStore runs each filter against SQLite and
+// against the memory store, which mirrors the Postgres semantics.
+func TestSQLiteFiltersMatchMemoryStore(t *testing.T) {
+	logger := zerolog.Nop()
+	stores := map[string]StringStore{
+		"sqlite": newTestSQLiteStore(t),
+		"memory": NewMemoryStore(&logger),
+	}
+	for _, s := range stores {
+		seedFilterFixtures(t, s)
+	}
+
+	everything := []string{"100% sure", "Racecar", "snake_case", "Ärger im Büro"}
+	for _, tc := range []struct {
+		name   string
+		params dto.QueryParams
+		want   []string
+	}{
+		// Expired and trashed strings are never returned.
+		{"no filters", dto.QueryParams{}, everything},
+
+		{"contains ignores case", dto.QueryParams{ContainsCharacter: "R"}, []string{"100% sure", "Racecar", "Ärger im Büro"}},
+		{"contains folds non-ASCII", dto.QueryParams{ContainsCharacter: "ä"}, []string{"Ärger im Büro"}},
+		{"contains folds non-ASCII upper case", dto.QueryParams{ContainsCharacter: "Ü"}, []string{"Ärger im Büro"}},
+		{"contains nothing", dto.QueryParams{ContainsCharacter: "z"}, []string{}},
+		// Like ILIKE, the LIKE wildcards keep their meaning.
+		{"contains %", dto.QueryParams{ContainsCharacter: "%"}, everything},
+		{"contains _", dto.QueryParams{ContainsCharacter: "_"}, everything},
+
+		{"metadata", dto.QueryParams{Metadata: map[string]any{"lang": "en"}}, []string{"Racecar"}},
+		{"metadata number", dto.QueryParams{Metadata: map[string]any{"score": float64(2)}}, []string{"snake_case"}},
+		{"metadata nested", dto.QueryParams{Metadata: map[string]any{"nested": map[string]any{"b": float64(2)}}}, []string{"Racecar"}},
+		{"metadata mismatch", dto.QueryParams{Metadata: map[string]any{"lang": "fr"}}, []string{}},
+		{"has_metadata", dto.QueryParams{MetadataKeys: []string{"lang"}}, []string{"Racecar", "Ärger im Büro"}},
+		{"has_metadata all keys", dto.QueryParams{MetadataKeys: []string{"lang", "score"}}, []string{"Racecar"}},
+
+		{"tag", dto.QueryParams{Tags: []string{"car"}}, []string{"Racecar", "snake_case"}},
+		{"every tag", dto.QueryParams{Tags: []string{"car", "palindrome"}}, []string{"Racecar"}},
+		{"unknown tag", dto.QueryParams{Tags: []string{"car", "missing"}}, []string{}},
+		{"collection", dto.QueryParams{Collection: "favourites"}, []string{"Racecar"}},
+		{"unknown collection", dto.QueryParams{Collection: "missing"}, []string{}},
+
+		{"combined", dto.QueryParams{ContainsCharacter: "e", Tags: []string{"car"}, MetadataKeys: []string{"score"}}, []string{"Racecar", "snake_case"}},
+	} {
+		for name, s := range stores {
+			got := []string{}
+			err := s.GetFilteredStrings(context.Background(), DefaultNamespace, tc.params, SelectAll,
+				func(summary ResultSummary) error {
+					if summary.Count != len(tc.want) {
+						t.Errorf("%s on %s: summary count %d, want %d", tc.name, name, summary.Count, len(tc.want))
+					}
+					return nil
+				},
+				func(match *model.StringMatch) error {
+					got = append(got, match.StringValue)
+					return nil
+				},
+			)
+			if err != nil {
+				t.Errorf("%s on %s: %v", tc.name, name, err)
+				continue
+			}
+
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%s on %s: got %q, want %q", tc.name, name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestSQLiteSoftDeleteAndExpiry(t *testing.T) {
+	s := newTestSQLiteStore(t)
+	seedFilterFixtures(t, s)
+	ctx := context.Background()
+
+	for _, value := range []string{"expired racecar", "deleted racecar"} {
+		if record, err := s.GetStringByValue(ctx, DefaultNamespace, value); err != nil || record != nil {
+			t.Errorf("%q: got %v, %v; want it hidden", value, record, err)
+		}
+	}
+
+	trash, err := s.ListTrash(ctx, DefaultNamespace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || trash[0].StringValue != "deleted racecar" || trash[0].DeletedAt == nil {
+		t.Fatalf("trash = %+v, want only the deleted string", trash)
+	}
+
+	if _, err := s.RestoreString(ctx, DefaultNamespace, util.Hash("deleted racecar")); err != nil {
+		t.Fatal(err)
+	}
+	if record, err := s.GetStringByValue(ctx, DefaultNamespace, "deleted racecar"); err != nil || record == nil {
+		t.Errorf("restored string: got %v, %v", record, err)
+	}
+}
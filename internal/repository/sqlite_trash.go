@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+func (r *SQLiteStore) ListTrash(ctx context.Context, namespace string) ([]model.String, error) {
+	stmt := `
+		SELECT
+			` + sqliteStringColumns + `
+		FROM
+			strings s
+		WHERE
+			s.namespace = @namespace
+			AND ` + sqliteTrashedString + `
+		ORDER BY
+			s.deleted_at DESC
+	`
+
+	rows, err := r.db.SQL.QueryContext(ctx, stmt, sql.Named("namespace", namespace))
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List trash query failed!")
+		return nil, fmt.Errorf("failed to execute list trash query: %w", err)
+	}
+
+	records, err := collectSQLiteStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:strings: %w", err)
+	}
+
+	return records, nil
+}
+
+func (r *SQLiteStore) RestoreString(ctx context.Context, namespace string, hash string) (*model.String, error) {
+	var restored *model.String
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		before, err := snapshotSQLiteString(ctx, tx, namespace, hash, sqliteTrashedString)
+		if err != nil {
+			return err
+		}
+
+		stmt := `UPDATE strings SET deleted_at = NULL WHERE namespace = @namespace AND sha256_hash = @sha256_hash`
+
+		if _, err = tx.ExecContext(ctx, stmt,
+			sql.Named("namespace", namespace),
+			sql.Named("sha256_hash", hash),
+		); err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Restore query failed!")
+			return fmt.Errorf("failed to execute restore string query: %w", err)
+		}
+
+		after, err := snapshotSQLiteString(ctx, tx, namespace, hash, "TRUE")
+		if err != nil {
+			return err
+		}
+
+		if err = recordSQLiteAudit(ctx, tx, auditEntry{
+			Namespace: namespace,
+			Operation: model.AuditRestore,
+			Hash:      hash,
+			Before:    before,
+			After:     after,
+		}); err != nil {
+			return err
+		}
+
+		restored, err = getSQLiteString(ctx, tx, namespace, hash, "TRUE")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return restored, nil
+}
+
+// removeSQLiteStrings deletes the strings with the given row IDs, writing each
+// removal to the audit log under operation.
+func removeSQLiteStrings(ctx context.Context, tx *sql.Tx, ids []int64, operation string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	encoded, err := sqliteJSON(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	stmt := `
+		INSERT INTO audit_events (namespace, actor, request_id, operation, sha256_hash, before)
+		SELECT s.namespace, @actor, @request_id, @operation, s.sha256_hash, ` + sqliteSnapshotColumn + `
+		FROM strings s
+		WHERE s.id IN (SELECT value FROM json_each(@ids))
+	`
+
+	if _, err = tx.ExecContext(ctx, stmt,
+		sql.Named("actor", requestctx.Actor(ctx)),
+		sql.Named("request_id", nullable(requestctx.RequestID(ctx))),
+		sql.Named("operation", operation),
+		sql.Named("ids", encoded),
+	); err != nil {
+		return 0, fmt.Errorf("failed to record audit events: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM strings WHERE id IN (SELECT value FROM json_each(@ids))`,
+		sql.Named("ids", encoded),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete strings: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// selectSQLiteIDs runs a query returning string row IDs.
+func selectSQLiteIDs(ctx context.Context, tx *sql.Tx, stmt string, args ...any) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// PurgeDeleted permanently removes strings, in every namespace, that were
+// moved to the trash before cutoff, auditing each removal.
+func (r *SQLiteStore) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	var removed int64
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		ids, err := selectSQLiteIDs(ctx, tx, `SELECT id FROM strings WHERE deleted_at < @cutoff`,
+			sql.Named("cutoff", sqliteTime(&cutoff)),
+		)
+		if err != nil {
+			return err
+		}
+
+		removed, err = removeSQLiteStrings(ctx, tx, ids, model.AuditPurge)
+		return err
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Purge trash query failed!")
+		return 0, fmt.Errorf("failed to execute purge trash query: %w", err)
+	}
+
+	return removed, nil
+}
+
+// ReapExpired permanently removes up to batchSize expired strings across all
+// namespaces, auditing each removal.
+func (r *SQLiteStore) ReapExpired(ctx context.Context, batchSize int) (int64, error) {
+	var removed int64
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		stmt := `
+			SELECT id FROM strings
+			WHERE expires_at <= ` + sqliteNow + `
+			ORDER BY expires_at
+			LIMIT @batch_size
+		`
+
+		ids, err := selectSQLiteIDs(ctx, tx, stmt, sql.Named("batch_size", batchSize))
+		if err != nil {
+			return err
+		}
+
+		removed, err = removeSQLiteStrings(ctx, tx, ids, model.AuditExpire)
+		return err
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Reap expired query failed!")
+		return 0, fmt.Errorf("failed to execute reap expired query: %w", err)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+)
+
+// Precondition is checked against the current state of a string inside the
+// write that would change it, and aborts the write by returning an error,
+// such as errs.ErrPreconditionFailed. A nil Precondition always passes.
+type Precondition func(current *model.String) error
+
+// Selection names the parts of a string a filtered query returns besides
+// its own columns. Parts that are not selected are not computed: tags and
+// collections come back nil and snippets empty.
+type Selection struct {
+	Tags        bool
+	Collections bool
+	Snippet     bool
+}
+
+// SelectAll selects every part of a string.
+var SelectAll = Selection{Tags: true, Collections: true, Snippet: true}
+
+// ResultSummary describes the whole result of a streamed query. It is
+// reported before the first row, so that a response can be headed with the
+// count and checked against an ETag without holding the rows.
+type ResultSummary struct {
+	Count int `db:"result_count"`
+	// Digest changes whenever any row of the result does.
+	Digest int64 `db:"result_digest"`
+}
+
+// StringStore persists analyzed strings together with their tags,
+// collections, metadata and lifecycle state. Every method is scoped to a
+// namespace except the background maintenance ones, which sweep all of them.
+type StringStore interface {
+	// GetFilteredStrings and GetFilteredStringsByNaturalLanguage stream
+	// their results: start is called once with the summary of the whole
+	// result, then row once per string in order. An error from either
+	// callback stops the query and is returned as it is.
+	GetFilteredStrings(ctx context.Context, namespace string, params dto.QueryParams, selection Selection, start func(ResultSummary) error, row func(*model.StringMatch) error) error
+	GetStringByValue(ctx context.Context, namespace string, value string) (*model.String, error)
+	GetStringByHash(ctx context.Context, namespace string, hash string) (*model.String, error)
+	CreateString(ctx context.Context, namespace string, payload *dto.CreateString) (*model.String, error)
+	PatchMetadata(ctx context.Context, namespace string, hash string, patch any, precondition Precondition) (*model.String, error)
+	DeleteString(ctx context.Context, namespace string, value string, hard bool, precondition Precondition) error
+	GetFilteredStringsByNaturalLanguage(ctx context.Context, namespace string, params *dto.FilterParams, selection Selection, start func(ResultSummary) error, row func(*model.String) error) error
+
+	AddTags(ctx context.Context, namespace string, hash string, tags []string) ([]string, error)
+	RemoveTag(ctx context.Context, namespace string, hash string, tag string) error
+
+	ListCollections(ctx context.Context, namespace string) ([]model.Collection, error)
+	CreateCollection(ctx context.Context, namespace string, name string) (*model.Collection, error)
+	DeleteCollection(ctx context.Context, namespace string, name string) error
+	AddToCollection(ctx context.Context, namespace string, name string, hash string) error
+	RemoveFromCollection(ctx context.Context, namespace string, name string, hash string) error
+
+	ListTrash(ctx context.Context, namespace string) ([]model.String, error)
+	RestoreString(ctx context.Context, namespace string, hash string) (*model.String, error)
+	PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error)
+	ReapExpired(ctx context.Context, batchSize int) (int64, error)
+}
+
+// NamespaceStore manages the namespaces strings are isolated in.
+type NamespaceStore interface {
+	ListNamespaces(ctx context.Context) ([]model.Namespace, error)
+	NamespaceExists(ctx context.Context, name string) (bool, error)
+	CreateNamespace(ctx context.Context, name string) (*model.Namespace, error)
+	DeleteNamespace(ctx context.Context, name string) error
+}
+
+// AuditStore reads the audit log that the other stores append to.
+type AuditStore interface {
+	ListAuditEvents(ctx context.Context, params dto.AuditQuery) ([]model.AuditEvent, error)
+}
+
+// APIKeyStore keeps the hashed API keys requests authenticate with.
+type APIKeyStore interface {
+	ListAPIKeys(ctx context.Context) ([]model.APIKey, error)
+	// GetAPIKeyByHash returns the unrevoked key with the given hash.
+	GetAPIKeyByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	CreateAPIKey(ctx context.Context, payload *dto.StoreAPIKey) (*model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id int64) error
+}
+
+var (
+	_ StringStore    = (*StringRepository)(nil)
+	_ NamespaceStore = (*NamespaceRepository)(nil)
+	_ AuditStore     = (*AuditRepository)(nil)
+	_ APIKeyStore    = (*APIKeyRepository)(nil)
+
+	_ StringStore    = (*MemoryStore)(nil)
+	_ NamespaceStore = (*MemoryStore)(nil)
+	_ AuditStore     = (*MemoryStore)(nil)
+	_ APIKeyStore    = (*MemoryStore)(nil)
+
+	_ StringStore    = (*SQLiteStore)(nil)
+	_ NamespaceStore = (*SQLiteStore)(nil)
+	_ AuditStore     = (*SQLiteStore)(nil)
+	_ APIKeyStore    = (*SQLiteStore)(nil)
+)
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+// stringExists reports errs.ErrNotFound when no string with the given hash is
+// stored in the namespace.
+func stringExists(ctx context.Context, tx pgx.Tx, namespace string, hash string) error {
+	var exists bool
+	stmt := `
+		SELECT EXISTS (
+			SELECT 1 FROM strings
+			WHERE namespace = @namespace AND sha256_hash = @sha256_hash AND ` + liveString + `
+		)
+	`
+	err := tx.QueryRow(ctx, stmt, pgx.NamedArgs{
+		"namespace":   namespace,
+		"sha256_hash": hash,
+	}).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check string existence: %w", err)
+	}
+
+	if !exists {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *StringRepository) AddTags(ctx context.Context, namespace string, hash string, tags []string) ([]string, error) {
+	var result []string
+
+	err := pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		if err := stringExists(ctx, tx, namespace, hash); err != nil {
+			return err
+		}
+
+		stmt := `
+			INSERT INTO string_tags (namespace, sha256_hash, tag)
+			SELECT @namespace, @sha256_hash, unnest(@tags::text[])
+			ON CONFLICT DO NOTHING
+		`
+
+		if _, err := tx.Exec(ctx, stmt, pgx.NamedArgs{
+			"namespace":   namespace,
+			"sha256_hash": hash,
+			"tags":        tags,
+		}); err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Add tags query failed!")
+			return fmt.Errorf("failed to execute add tags query: %w", err)
+		}
+
+		stmt = `SELECT tag FROM string_tags WHERE namespace = @namespace AND sha256_hash = @sha256_hash ORDER BY tag`
+		rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+			"namespace":   namespace,
+			"sha256_hash": hash,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to execute list tags query: %w", err)
+		}
+
+		result, err = pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("failed to collect rows from table:string_tags: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *StringRepository) RemoveTag(ctx context.Context, namespace string, hash string, tag string) error {
+	stmt := `DELETE FROM string_tags WHERE namespace = @namespace AND sha256_hash = @sha256_hash AND tag = @tag`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"namespace":   namespace,
+		"sha256_hash": hash,
+		"tag":         tag,
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Remove tag query failed!")
+		return fmt.Errorf("failed to execute remove tag query: %w", err)
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return errs.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/model"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+)
+
+func (r *StringRepository) ListTrash(ctx context.Context, namespace string) ([]model.String, error) {
+	stmt := `
+		SELECT
+			` + stringColumns + `
+		FROM
+			strings
+		WHERE
+			namespace = @namespace
+			AND ` + trashedString + `
+		ORDER BY
+			deleted_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, stmt, pgx.NamedArgs{
+		"namespace": namespace,
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("List trash query failed!")
+		return nil, fmt.Errorf("failed to execute list trash query: %w", err)
+	}
+
+	records, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.String])
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect rows from table:strings: %w", err)
+	}
+
+	return records, nil
+}
+
+func (r *StringRepository) RestoreString(ctx context.Context, namespace string, hash string) (*model.String, error) {
+	var restored model.String
+
+	err := pgx.BeginFunc(ctx, r.db.Pool, func(tx pgx.Tx) error {
+		before, err := snapshotString(ctx, tx, namespace, hash, trashedString)
+		if err != nil {
+			return err
+		}
+
+		stmt := `
+			UPDATE strings
+			SET deleted_at = NULL
+			WHERE namespace = @namespace AND sha256_hash = @sha256_hash
+			RETURNING ` + stringColumns
+
+		rows, err := tx.Query(ctx, stmt, pgx.NamedArgs{
+			"namespace":   namespace,
+			"sha256_hash": hash,
+		})
+		if err != nil {
+			requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Restore query failed!")
+			return fmt.Errorf("failed to execute restore string query: %w", err)
+		}
+
+		restored, err = pgx.CollectOneRow(rows, pgx.RowToStructByName[model.String])
+		if err != nil {
+			return fmt.Errorf("failed to collect row from table:strings: %w", err)
+		}
+
+		after, err := snapshotString(ctx, tx, namespace, hash, "TRUE")
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, auditEntry{
+			Namespace: namespace,
+			Operation: model.AuditRestore,
+			Hash:      hash,
+			Before:    before,
+			After:     after,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &restored, nil
+}
+
+// PurgeDeleted permanently removes strings, in every namespace, that were
+// moved to the trash before cutoff. Each removal is written to the audit log.
+// It returns the number of strings removed.
+func (r *StringRepository) PurgeDeleted(ctx context.Context, cutoff time.Time) (int64, error) {
+	stmt := `
+		WITH purged AS (
+			DELETE FROM strings
+			WHERE deleted_at < @cutoff
+			RETURNING namespace, sha256_hash, ` + snapshotColumn + ` AS before
+		)
+		INSERT INTO audit_events (namespace, actor, request_id, operation, sha256_hash, before)
+		SELECT namespace, @actor, @request_id, @operation, sha256_hash, before
+		FROM purged
+	`
+
+	cmdTag, err := r.db.Pool.Exec(ctx, stmt, pgx.NamedArgs{
+		"cutoff":     cutoff,
+		"actor":      requestctx.Actor(ctx),
+		"request_id": nullable(requestctx.RequestID(ctx)),
+		"operation":  model.AuditPurge,
+	})
+	if err != nil {
+		requestctx.Logger(ctx, r.logger).Error().Err(err).Msg("Purge trash query failed!")
+		return 0, fmt.Errorf("failed to execute purge trash query: %w", err)
+	}
+
+	return cmdTag.RowsAffected(), nil
+}
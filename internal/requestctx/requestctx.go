@@ -0,0 +1,78 @@
+package requestctx
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	// ActorHeader names the caller on whose behalf a request is made, used
+	// when no authenticated identity is available.
+	ActorHeader = "X-Actor"
+	// RequestIDHeader carries the identifier used to correlate a request
+	// across logs and the audit trail.
+	RequestIDHeader = "X-Request-ID"
+
+	// AnonymousActor is recorded when a request names no actor.
+	AnonymousActor = "anonymous"
+)
+
+type actorKey struct{}
+
+type requestIDKey struct{}
+
+type loggerKey struct{}
+
+// WithActor returns a copy of ctx that carries actor.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor stored in ctx, or AnonymousActor.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+// WithRequestID returns a copy of ctx that carries the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithLogger returns a copy of ctx that carries a request-scoped logger.
+func WithLogger(ctx context.Context, logger *zerolog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the request-scoped logger stored in ctx, or fallback when
+// ctx does not belong to a request, e.g. in background workers.
+func Logger(ctx context.Context, fallback *zerolog.Logger) *zerolog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zerolog.Logger); ok {
+		return logger
+	}
+	return fallback
+}
+
+// FromHeaders stores the actor sent by the client in the request context.
+// The request ID is handled by middleware.RequestID.
+func FromHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if actor := r.Header.Get(ActorHeader); actor != "" {
+			ctx = WithActor(ctx, actor)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
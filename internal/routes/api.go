@@ -0,0 +1,85 @@
+package routes
+
+import (
+	chi "github.com/go-chi/chi/v5"
+	"github.com/justinndidit/stringAnalyzer/internal/application"
+	"github.com/justinndidit/stringAnalyzer/internal/auth"
+	"github.com/justinndidit/stringAnalyzer/internal/handler"
+)
+
+// v1 mounts the first version of the API.
+func v1(app *application.Application) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Use(handler.UseVersion(handler.V1))
+		resources(app, r)
+	}
+}
+
+// v2 mounts the second version of the API. It has the same routes as v1 and
+// changes only the shape of responses; see handler.V2.
+func v2(app *application.Application) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Use(handler.UseVersion(handler.V2))
+		resources(app, r)
+	}
+}
+
+// resources registers the routes every version shares. A version that
+// adds, removes or changes a route should get its own table rather than a
+// condition in this one.
+func resources(app *application.Application, r chi.Router) {
+	// Every route below requires an API key carrying the scope named next to
+	// it; admin keys pass every check. Limiter.Limit runs after chi has
+	// matched each route so that limits apply per route pattern, and
+	// Limiter.LimitAuthFailures before Authenticate so that failed attempts
+	// are throttled by IP address.
+	r.Group(func(r chi.Router) {
+		r.Use(app.Limiter.LimitAuthFailures, app.Auth.Authenticate)
+
+		read := app.Auth.Require(auth.ScopeRead)
+		write := app.Auth.Require(auth.ScopeWrite)
+		del := app.Auth.Require(auth.ScopeDelete)
+		admin := app.Auth.Require(auth.ScopeAdmin)
+
+		r.Group(func(r chi.Router) {
+			r.Use(app.Limiter.Limit)
+			r.Use(app.Handler.ResolveNamespace)
+
+			r.With(write).Post("/strings", app.Handler.UploadString)
+			r.With(read).Get("/strings", app.Handler.GetFilteredStrings)
+			r.With(read).Get("/strings/{string_value}", app.Handler.GetString)
+			r.With(read).Get("/strings/filter-by-natural-language", app.Handler.FilterByNaturalLanguage)
+			r.With(read).Get("/strings/trash", app.Handler.ListTrash)
+			r.With(write).Post("/strings/{id}/restore", app.Handler.RestoreString)
+			r.With(write).Patch("/strings/{id}", app.Handler.PatchString)
+			r.With(del).Delete("/strings/{string_value}", app.Handler.DeleteString)
+			r.With(write).Post("/strings/{string_value}/tags", app.Handler.AddTags)
+			r.With(del).Delete("/strings/{string_value}/tags/{tag}", app.Handler.RemoveTag)
+
+			r.With(read).Get("/collections", app.Handler.ListCollections)
+			r.With(write).Post("/collections", app.Handler.CreateCollection)
+			r.With(del).Delete("/collections/{name}", app.Handler.DeleteCollection)
+			r.With(write).Put("/collections/{name}/strings/{string_value}", app.Handler.AddToCollection)
+			r.With(del).Delete("/collections/{name}/strings/{string_value}", app.Handler.RemoveFromCollection)
+		})
+
+		r.With(app.Limiter.Limit, admin).Get("/audit", app.Handler.ListAuditEvents)
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(admin)
+
+			r.Group(func(r chi.Router) {
+				r.Use(app.Limiter.Limit)
+
+				r.Get("/namespaces", app.Handler.ListNamespaces)
+				r.Get("/cache", app.Handler.CacheStats)
+				r.Post("/namespaces", app.Handler.CreateNamespace)
+				r.Delete("/namespaces/{name}", app.Handler.DeleteNamespace)
+
+				r.Get("/keys", app.Handler.ListAPIKeys)
+				r.Post("/keys", app.Handler.CreateAPIKey)
+				r.Delete("/keys/{id}", app.Handler.RevokeAPIKey)
+			})
+		})
+	})
+}
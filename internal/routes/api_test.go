@@ -0,0 +1,208 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/justinndidit/stringAnalyzer/internal/application"
+	"github.com/justinndidit/stringAnalyzer/internal/auth"
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/rs/zerolog"
+)
+
+const bootstrapKey = "bootstrap-key-for-the-routes-tests"
+
+// newTestServer serves every route against the memory store, with
+// authentication on and the bootstrap key as the only credential.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	for name, value := range map[string]string{
+		"DATABASE_DRIVER":             config.DriverMemory,
+		"SERVER_PORT":                 "8080",
+		"SERVER_READ_TIMEOUT":         "5",
+		"SERVER_WRITE_TIMEOUT":        "5",
+		"SERVER_IDLE_TIMEOUT":         "5",
+		"SERVER_CORS_ALLOWED_ORIGINS": "*",
+		"AUTH_BOOTSTRAP_KEY":          bootstrapKey,
+		// The cache registers its metrics globally, and a test server is
+		// created per test.
+		"CACHE_ENABLED":     "false",
+		"RATELIMIT_ENABLED": "false",
+	} {
+		t.Setenv(name, value)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := zerolog.Nop()
+	app, err := application.NewApp(cfg, &logger, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(SetupAuthRoutes(app))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do sends a request with key as a bearer token, if set, and decodes the
+// JSON response when there is one.
+func do(t *testing.T, server *httptest.Server, key, method, path, body string, headers ...string) (*http.Response, map[string]any) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var decoded map[string]any
+	if resp.StatusCode != http.StatusNoContent && strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+			t.Fatalf("%s %s: decoding the %d response: %v", method, path, resp.StatusCode, err)
+		}
+	}
+	return resp, decoded
+}
+
+// issueKey creates an API key with scopes through the admin API and
+// returns it with its record.
+func issueKey(t *testing.T, server *httptest.Server, scopes ...string) (string, map[string]any) {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]any{"name": strings.Join(scopes, "+"), "scopes": scopes})
+	resp, created := do(t, server, bootstrapKey, http.MethodPost, "/v1/admin/keys", string(body))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("creating a %v key: status %d, body %v", scopes, resp.StatusCode, created)
+	}
+	return created["key"].(string), created
+}
+
+func TestRoutesRequireTheirScope(t *testing.T) {
+	server := newTestServer(t)
+
+	keys := map[string]string{}
+	for _, scope := range auth.Scopes {
+		keys[scope], _ = issueKey(t, server, scope)
+	}
+
+	for _, route := range []struct {
+		method, path, body string
+		scope              string
+	}{
+		{http.MethodGet, "/v1/strings", "", auth.ScopeRead},
+		{http.MethodGet, "/v1/strings/racecar", "", auth.ScopeRead},
+		{http.MethodGet, "/v1/strings/trash", "", auth.ScopeRead},
+		{http.MethodGet, "/v1/collections", "", auth.ScopeRead},
+		{http.MethodGet, "/openapi.json", "", auth.ScopeRead},
+		{http.MethodPost, "/v1/strings", `{"value":"racecar"}`, auth.ScopeWrite},
+		{http.MethodPatch, "/v1/strings/abc", `{"metadata":{}}`, auth.ScopeWrite},
+		{http.MethodPost, "/v1/strings/racecar/tags", `{"tags":["a"]}`, auth.ScopeWrite},
+		{http.MethodPost, "/v1/collections", `{"name":"c"}`, auth.ScopeWrite},
+		{http.MethodDelete, "/v1/strings/missing", "", auth.ScopeDelete},
+		{http.MethodDelete, "/v1/strings/racecar/tags/a", "", auth.ScopeDelete},
+		{http.MethodDelete, "/v1/collections/missing", "", auth.ScopeDelete},
+		{http.MethodGet, "/v1/audit", "", auth.ScopeAdmin},
+		{http.MethodGet, "/v1/admin/namespaces", "", auth.ScopeAdmin},
+		{http.MethodGet, "/v1/admin/keys", "", auth.ScopeAdmin},
+		{http.MethodGet, "/v1/admin/cache", "", auth.ScopeAdmin},
+		{http.MethodGet, "/metrics", "", auth.ScopeAdmin},
+		// The v2 and deprecated unversioned routes are the same table.
+		{http.MethodDelete, "/v2/strings/missing", "", auth.ScopeDelete},
+		{http.MethodGet, "/v2/audit", "", auth.ScopeAdmin},
+		{http.MethodDelete, "/strings/missing", "", auth.ScopeDelete},
+		{http.MethodGet, "/admin/keys", "", auth.ScopeAdmin},
+	} {
+		name := route.method + " " + route.path
+
+		resp, body := do(t, server, "", route.method, route.path, route.body)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s without a key: status %d, want 401", name, resp.StatusCode)
+		}
+
+		for scope, key := range keys {
+			resp, body = do(t, server, key, route.method, route.path, route.body)
+			// admin implies every other scope.
+			allowed := scope == route.scope || scope == auth.ScopeAdmin
+			switch {
+			case allowed && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden):
+				t.Errorf("%s with a %s key: status %d, want it allowed; body %v", name, scope, resp.StatusCode, body)
+			case !allowed && (resp.StatusCode != http.StatusForbidden || body["code"] != "insufficient_scope"):
+				t.Errorf("%s with a %s key: status %d, want 403 insufficient_scope; body %v", name, scope, resp.StatusCode, body)
+			}
+		}
+	}
+}
+
+func TestRevokedKeyIsRejected(t *testing.T) {
+	server := newTestServer(t)
+	key, record := issueKey(t, server, auth.ScopeRead)
+
+	resp, body := do(t, server, key, http.MethodGet, "/v1/strings", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d before revoking; body %v", resp.StatusCode, body)
+	}
+
+	resp, body = do(t, server, bootstrapKey, http.MethodDelete, fmt.Sprintf("/v1/admin/keys/%v", record["id"]), "")
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("revoking: status %d; body %v", resp.StatusCode, body)
+	}
+
+	for _, header := range []string{"Authorization", auth.APIKeyHeader} {
+		value := key
+		if header == "Authorization" {
+			value = "Bearer " + key
+		}
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/strings", nil)
+		req.Header.Set(header, value)
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("revoked key in %s: status %d, want 401", header, resp.StatusCode)
+		}
+	}
+}
+
+func TestPrincipalOverridesActorHeader(t *testing.T) {
+	server := newTestServer(t)
+	key, record := issueKey(t, server, auth.ScopeWrite)
+
+	resp, body := do(t, server, key, http.MethodPost, "/v1/strings", `{"value":"racecar"}`, "X-Actor", "someone-else")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status %d; body %v", resp.StatusCode, body)
+	}
+
+	resp, body = do(t, server, bootstrapKey, http.MethodGet, "/v1/audit?operation=create", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("audit: status %d; body %v", resp.StatusCode, body)
+	}
+	events := body["data"].([]any)
+	if len(events) != 1 {
+		t.Fatalf("got %d create events, want 1", len(events))
+	}
+	if actor := events[0].(map[string]any)["actor"]; actor != "apikey:"+record["prefix"].(string) {
+		t.Errorf("actor = %v, want the key's subject", actor)
+	}
+}
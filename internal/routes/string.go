@@ -5,15 +5,55 @@ import (
 
 	chi "github.com/go-chi/chi/v5"
 	"github.com/justinndidit/stringAnalyzer/internal/application"
+	"github.com/justinndidit/stringAnalyzer/internal/auth"
+	"github.com/justinndidit/stringAnalyzer/internal/metrics"
+	"github.com/justinndidit/stringAnalyzer/internal/middleware"
+	"github.com/justinndidit/stringAnalyzer/internal/openapi"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/justinndidit/stringAnalyzer/internal/tracing"
 )
 
 func SetupAuthRoutes(app *application.Application) *chi.Mux {
 	r := chi.NewRouter()
-	r.Post("/strings", app.Handler.UploadString)
-	r.Get("/strings", app.Handler.GetFilteredStrings)
-	r.Get("/strings/{string_value}", app.Handler.GetString)
-	r.Get("/strings/filter-by-natural-language", app.Handler.FilterByNaturalLanguage)
-	r.Delete("/strings/{string_value}", app.Handler.DeleteString)
+	r.Use(tracing.Middleware)
+	r.Use(middleware.RequestID)
+	r.Use(middleware.AccessLog(app.Logger))
+	r.Use(middleware.CORS(&app.Config.Server))
+	r.Use(metrics.Middleware)
+	r.Use(middleware.Recoverer(app.Logger))
+	r.Use(middleware.MaxBodySize(app.Config.Server.MaxBodyBytes))
+	r.Use(requestctx.FromHeaders)
+
+	// The docs page only renders /openapi.json, which it fetches with the
+	// key the reader enters, so it is public either way.
+	r.Get("/docs", openapi.Docs)
+	r.Handle("/docs/assets/*", openapi.Assets())
+
+	r.Group(func(r chi.Router) {
+		if !app.Config.Auth.PublicMetrics {
+			r.Use(app.Limiter.LimitAuthFailures, app.Auth.Authenticate, app.Auth.Require(auth.ScopeAdmin))
+		}
+		r.Method(http.MethodGet, "/metrics", metrics.Handler())
+	})
+	r.Group(func(r chi.Router) {
+		if !app.Config.Auth.PublicDocs {
+			r.Use(app.Limiter.LimitAuthFailures, app.Auth.Authenticate, app.Auth.Require(auth.ScopeRead))
+		}
+		r.Get("/openapi.json", openapi.Handler)
+	})
+
+	r.Route("/v1", v1(app))
+	r.Route("/v2", v2(app))
+
+	// The unversioned paths predate /v1 and serve it until they are
+	// sunset.
+	if app.Config.API.LegacyPaths {
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.Deprecated(app.Config.API.LegacyDeprecation, app.Config.API.LegacySunset, "/v1"))
+			v1(app)(r)
+		})
+	}
+
 	r.Get("/kaithheathcheck", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -58,6 +58,13 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		Str("addr", s.httpServer.Addr).
 		Msg("HTTP server configured")
 
+	s.App.Purger.Stop()
+	s.App.Reaper.Stop()
+
+	if s.App.DB == nil {
+		return nil
+	}
+
 	if err := s.App.DB.Close(); err != nil {
 		return fmt.Errorf("failed to close database connection: %w", err)
 	}
@@ -12,6 +12,7 @@ import (
 	"unicode"
 
 	"github.com/justinndidit/stringAnalyzer/internal/dto"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
 )
 
 type Envelope map[string]any
@@ -40,12 +41,40 @@ func WriteJson(w http.ResponseWriter, status int, data Envelope) error {
 	return nil
 }
 
+// WriteProblem writes p as an application/problem+json response for r. The
+// instance is the request path and, when the request ID middleware ran, the
+// request ID is included so clients can quote it.
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *errs.Problem) error {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = w.Header().Get("X-Request-ID")
+	}
+
+	js, err := json.MarshalIndent(p, "", "")
+	if err != nil {
+		return err
+	}
+
+	js = append(js, '\n')
+	w.Header().Set("Content-Type", errs.ProblemContentType)
+	w.WriteHeader(p.Status)
+	w.Write(js)
+	return nil
+}
+
 // func WriteResponse(data any) *Envelope {
 // 	return &Envelope{
 // 		"": data,
 // 	}
 // }
 
+// AnalyzerVersion identifies the rules the analysis functions below follow.
+// It is part of every string's ETag, so bump it whenever a change to them
+// would alter a computed property.
+const AnalyzerVersion = 1
+
 func CountWords(s string) int {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -131,6 +160,112 @@ func CharacterCount(s string) int {
 	return len([]rune(s))
 }
 
+// MergePatch applies a JSON Merge Patch (RFC 7396) to target and returns the
+// result. Object members set to null in the patch are removed; any other
+// patch value replaces the target wholesale.
+func MergePatch(target any, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = map[string]any{}
+	}
+
+	result := make(map[string]any, len(targetObj))
+	for key, value := range targetObj {
+		result[key] = value
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = MergePatch(result[key], value)
+	}
+
+	return result
+}
+
+// JSONContains reports whether the decoded JSON value doc contains sub, with
+// the semantics of the Postgres jsonb @> operator.
+func JSONContains(doc, sub any) bool {
+	switch subVal := sub.(type) {
+	case map[string]any:
+		docVal, ok := doc.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range subVal {
+			inner, ok := docVal[key]
+			if !ok || !JSONContains(inner, value) {
+				return false
+			}
+		}
+		return true
+
+	case []any:
+		docVal, ok := doc.([]any)
+		if !ok {
+			return false
+		}
+		for _, want := range subVal {
+			found := false
+			for _, have := range docVal {
+				if JSONContains(have, want) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+
+	default:
+		if docVal, ok := doc.([]any); ok {
+			for _, have := range docVal {
+				if have == sub {
+					return true
+				}
+			}
+			return false
+		}
+		return doc == sub
+	}
+}
+
+// ParseMetadataValue interprets a metadata filter value from a query string.
+// JSON numbers, booleans and null keep their type so "?metadata.version=2"
+// matches {"version": 2}; everything else is compared as a string.
+func ParseMetadataValue(raw string) any {
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err == nil {
+		switch value.(type) {
+		case float64, bool, nil:
+			return value
+		}
+	}
+	return raw
+}
+
+var namespacePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// IsValidNamespace reports whether name can be used as a namespace.
+func IsValidNamespace(name string) bool {
+	return namespacePattern.MatchString(name)
+}
+
+// NormalizeTag trims and lowercases a tag so "Dataset-A " and "dataset-a"
+// refer to the same tag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
 func ParseNaturalLanguageQuery(query string) (*dto.FilterParams, map[string]interface{}, error) {
 	query = strings.ToLower(strings.TrimSpace(query))
 	filters := &dto.FilterParams{}
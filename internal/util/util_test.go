@@ -0,0 +1,55 @@
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestMergePatch runs the examples from RFC 7396, Appendix A.
+func TestMergePatch(t *testing.T) {
+	for _, tc := range []struct {
+		target, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	} {
+		var target, patch, want any
+		for _, v := range []struct {
+			raw  string
+			into *any
+		}{{tc.target, &target}, {tc.patch, &patch}, {tc.want, &want}} {
+			if err := json.Unmarshal([]byte(v.raw), v.into); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if got := MergePatch(target, patch); !reflect.DeepEqual(got, want) {
+			t.Errorf("MergePatch(%s, %s) = %v, want %s", tc.target, tc.patch, got, tc.want)
+		}
+	}
+}
+
+func TestMergePatchLeavesTargetUnchanged(t *testing.T) {
+	target := map[string]any{"a": "b", "nested": map[string]any{"c": "d"}}
+
+	MergePatch(target, map[string]any{"a": nil, "nested": map[string]any{"c": "e"}})
+
+	want := map[string]any{"a": "b", "nested": map[string]any{"c": "d"}}
+	if !reflect.DeepEqual(target, want) {
+		t.Errorf("target changed to %v", target)
+	}
+}
@@ -0,0 +1,37 @@
+package util
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+)
+
+// CheckValue enforces the length limits and content policies in cfg on an
+// uploaded value. Size violations wrap errs.ErrValueTooLarge; content
+// violations wrap errs.ErrNULByte or errs.ErrControlCharacter.
+func CheckValue(value string, cfg *config.ValueConfig) error {
+	if len(value) > cfg.MaxBytes {
+		return fmt.Errorf("%w: %d bytes, at most %d allowed", errs.ErrValueTooLarge, len(value), cfg.MaxBytes)
+	}
+	if n := utf8.RuneCountInString(value); n > cfg.MaxRunes {
+		return fmt.Errorf("%w: %d characters, at most %d allowed", errs.ErrValueTooLarge, n, cfg.MaxRunes)
+	}
+
+	for i, r := range value {
+		switch {
+		case r == 0:
+			if cfg.NULBytes == config.PolicyReject {
+				return fmt.Errorf("%w at byte %d", errs.ErrNULByte, i)
+			}
+		case r == '\t' || r == '\n' || r == '\r':
+		case unicode.IsControl(r):
+			if cfg.ControlCharacters == config.PolicyReject {
+				return fmt.Errorf("%w (U+%04X) at byte %d", errs.ErrControlCharacter, r, i)
+			}
+		}
+	}
+	return nil
+}
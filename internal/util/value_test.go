@@ -0,0 +1,59 @@
+package util
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/errs"
+)
+
+func TestCheckValue(t *testing.T) {
+	strict := config.ValueConfig{
+		MaxBytes:          8,
+		MaxRunes:          4,
+		NULBytes:          config.PolicyReject,
+		ControlCharacters: config.PolicyReject,
+	}
+	lenient := strict
+	lenient.NULBytes = config.PolicyAllow
+	lenient.ControlCharacters = config.PolicyAllow
+
+	for _, tc := range []struct {
+		name  string
+		value string
+		cfg   config.ValueConfig
+		want  error
+	}{
+		{"empty", "", strict, nil},
+		{"at the limits", "éééé", strict, nil},
+		{"too many bytes", "ééééé", strict, errs.ErrValueTooLarge},
+		{"too many characters", "abcde", strict, errs.ErrValueTooLarge},
+		{"whitespace controls", "a\tb\n", strict, nil},
+		{"carriage return", "a\r\n", strict, nil},
+		{"NUL rejected", "a\x00b", strict, errs.ErrNULByte},
+		{"NUL allowed", "a\x00b", lenient, nil},
+		{"control rejected", "a\x1bb", strict, errs.ErrControlCharacter},
+		{"C1 control rejected", "a\u0085", strict, errs.ErrControlCharacter},
+		{"control allowed", "a\x1bb", lenient, nil},
+		{"size checked before content", strings.Repeat("\x00", 9), lenient, errs.ErrValueTooLarge},
+	} {
+		err := CheckValue(tc.value, &tc.cfg)
+		switch {
+		case tc.want == nil && err != nil:
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		case tc.want != nil && !errors.Is(err, tc.want):
+			t.Errorf("%s: error %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestCheckValueReportsPosition(t *testing.T) {
+	cfg := config.ValueConfig{MaxBytes: 64, MaxRunes: 64, NULBytes: config.PolicyReject, ControlCharacters: config.PolicyReject}
+
+	err := CheckValue("héllo\x07", &cfg)
+	if err == nil || !strings.Contains(err.Error(), "U+0007") || !strings.Contains(err.Error(), "byte 6") {
+		t.Errorf("error %v, want the character and its byte offset", err)
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/rs/zerolog"
+)
+
+// PurgerActor is recorded in the audit log for strings the purger removes.
+const PurgerActor = "system:trash-purger"
+
+// TrashPurger periodically removes strings that have been in the trash for
+// longer than the configured retention period.
+type TrashPurger struct {
+	periodic
+
+	logger    *zerolog.Logger
+	repo      repository.StringStore
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewTrashPurger(logger *zerolog.Logger, repo repository.StringStore, cfg *config.Config) *TrashPurger {
+	return &TrashPurger{
+		logger:    logger,
+		repo:      repo,
+		retention: time.Duration(cfg.Trash.Retention) * time.Second,
+		interval:  time.Duration(cfg.Trash.PurgeInterval) * time.Second,
+	}
+}
+
+// Start runs the purge loop in the background until Stop is called.
+func (p *TrashPurger) Start() {
+	p.logger.Info().
+		Dur("retention", p.retention).
+		Dur("interval", p.interval).
+		Msg("trash purger started")
+
+	p.start(requestctx.WithActor(context.Background(), PurgerActor), p.interval, p.purge)
+}
+
+// Stop cancels the purge loop and waits for an in-flight purge to finish.
+func (p *TrashPurger) Stop() {
+	if p.stop() {
+		p.logger.Info().Msg("trash purger stopped")
+	}
+}
+
+func (p *TrashPurger) purge(ctx context.Context) {
+	removed, err := p.repo.PurgeDeleted(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error().Err(err).Msg("failed to purge trash")
+		}
+		return
+	}
+
+	if removed > 0 {
+		p.logger.Info().Int64("removed", removed).Msg("purged expired strings from trash")
+	}
+}
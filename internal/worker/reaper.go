@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/justinndidit/stringAnalyzer/internal/config"
+	"github.com/justinndidit/stringAnalyzer/internal/repository"
+	"github.com/justinndidit/stringAnalyzer/internal/requestctx"
+	"github.com/rs/zerolog"
+)
+
+// ReaperActor is recorded in the audit log for strings the reaper removes.
+const ReaperActor = "system:expiry-reaper"
+
+// ExpiryReaper periodically deletes strings whose expires_at has passed.
+// Reads already hide expired strings; the reaper reclaims their storage.
+type ExpiryReaper struct {
+	periodic
+
+	logger    *zerolog.Logger
+	repo      repository.StringStore
+	interval  time.Duration
+	batchSize int
+}
+
+func NewExpiryReaper(logger *zerolog.Logger, repo repository.StringStore, cfg *config.Config) *ExpiryReaper {
+	return &ExpiryReaper{
+		logger:    logger,
+		repo:      repo,
+		interval:  time.Duration(cfg.Expiry.ReapInterval) * time.Second,
+		batchSize: cfg.Expiry.BatchSize,
+	}
+}
+
+// Start runs the reap loop in the background until Stop is called.
+func (e *ExpiryReaper) Start() {
+	e.logger.Info().
+		Dur("interval", e.interval).
+		Int("batch_size", e.batchSize).
+		Msg("expiry reaper started")
+
+	e.start(requestctx.WithActor(context.Background(), ReaperActor), e.interval, e.reap)
+}
+
+// Stop cancels the reap loop and waits for an in-flight batch to finish.
+func (e *ExpiryReaper) Stop() {
+	if e.stop() {
+		e.logger.Info().Msg("expiry reaper stopped")
+	}
+}
+
+// reap deletes expired strings batch by batch until a short batch shows
+// nothing is left, then reports the total.
+func (e *ExpiryReaper) reap(ctx context.Context) {
+	var total int64
+
+	for ctx.Err() == nil {
+		removed, err := e.repo.ReapExpired(ctx, e.batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				e.logger.Error().Err(err).Msg("failed to reap expired strings")
+			}
+			break
+		}
+
+		total += removed
+		if removed < int64(e.batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		e.logger.Info().Int64("removed", total).Msg("reaped expired strings")
+	}
+}
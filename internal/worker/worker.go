@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// periodic runs a task on a fixed interval in a background goroutine.
+type periodic struct {
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// start runs task immediately and then every interval until stop is called.
+func (p *periodic) start(ctx context.Context, interval time.Duration, task func(context.Context)) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			task(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// stop cancels the loop and waits for an in-flight run to finish. It reports
+// whether the loop had been started.
+func (p *periodic) stop() bool {
+	if p.cancel == nil {
+		return false
+	}
+
+	p.cancel()
+	p.wg.Wait()
+	return true
+}